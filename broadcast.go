@@ -9,6 +9,8 @@ package whatsmeow
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	waBinary "github.com/sofyan48/whatsmeow/binary"
 	types "github.com/sofyan48/whatsmeow/types"
@@ -19,6 +21,8 @@ func (cli *Client) getBroadcastListParticipants(jid types.JID) ([]types.JID, err
 	var err error
 	if jid == types.StatusBroadcastJID {
 		list, err = cli.getStatusBroadcastRecipients()
+	} else if jid.IsBroadcastList() {
+		list, err = cli.getBroadcastListRecipients(jid)
 	} else {
 		return nil, ErrBroadcastListUnsupported
 	}
@@ -48,6 +52,17 @@ func (cli *Client) getBroadcastListParticipants(jid types.JID) ([]types.JID, err
 	return list, nil
 }
 
+func (cli *Client) getBroadcastListRecipients(jid types.JID) ([]types.JID, error) {
+	info, err := cli.Store.BroadcastLists.GetBroadcastList(jid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast list from db: %w", err)
+	} else if info == nil {
+		return nil, ErrBroadcastListNotFound
+	}
+	// Copy the list, as getBroadcastListParticipants may modify it in place
+	return append([]types.JID{}, info.Recipients...), nil
+}
+
 func (cli *Client) getStatusBroadcastRecipients() ([]types.JID, error) {
 	statusPrivacyOptions, err := cli.GetStatusPrivacy()
 	if err != nil {
@@ -145,3 +160,99 @@ func (cli *Client) GetStatusPrivacy() ([]types.StatusPrivacy, error) {
 	}
 	return outputs, nil
 }
+
+// CreateBroadcastList creates a new broadcast list with the given name and recipients.
+//
+// Broadcast lists are stored in the device store, so they will not show up on other linked devices.
+// Lists created on the phone are not synced to the store either, so only lists created with this
+// method can be used for sending.
+// Messages can be sent to the list by passing the returned JID to SendMessage, in which case each recipient
+// will receive the message in their direct chat with you.
+func (cli *Client) CreateBroadcastList(name string, recipients []types.JID) (*types.BroadcastListInfo, error) {
+	if len(recipients) == 0 {
+		return nil, ErrBroadcastListEmpty
+	}
+	now := time.Now()
+	// Broadcast list IDs are creation timestamps, bump the ID if there's a collision with an existing list.
+	var jid types.JID
+	for id := now.Unix(); ; id++ {
+		jid = types.NewJID(strconv.FormatInt(id, 10), types.BroadcastServer)
+		existing, err := cli.Store.BroadcastLists.GetBroadcastList(jid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for existing broadcast list: %w", err)
+		} else if existing == nil {
+			break
+		}
+	}
+	info := &types.BroadcastListInfo{
+		JID:        jid,
+		Name:       name,
+		CreatedAt:  now,
+		Recipients: make([]types.JID, len(recipients)),
+	}
+	for i, recipient := range recipients {
+		info.Recipients[i] = recipient.ToNonAD()
+	}
+	err := cli.Store.BroadcastLists.PutBroadcastList(*info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save broadcast list: %w", err)
+	}
+	return info, nil
+}
+
+// GetBroadcastLists returns all broadcast lists stored for the current user.
+//
+// This only includes lists created with CreateBroadcastList, the phone's existing broadcast lists are not visible.
+func (cli *Client) GetBroadcastLists() ([]types.BroadcastListInfo, error) {
+	return cli.Store.BroadcastLists.GetAllBroadcastLists()
+}
+
+// GetBroadcastListInfo returns the name and recipients of the given broadcast list.
+func (cli *Client) GetBroadcastListInfo(jid types.JID) (*types.BroadcastListInfo, error) {
+	if !jid.IsBroadcastList() {
+		return nil, ErrNotBroadcastList
+	}
+	info, err := cli.Store.BroadcastLists.GetBroadcastList(jid)
+	if err != nil {
+		return nil, err
+	} else if info == nil {
+		return nil, ErrBroadcastListNotFound
+	}
+	return info, nil
+}
+
+// SetBroadcastListName renames the given broadcast list.
+func (cli *Client) SetBroadcastListName(jid types.JID, name string) error {
+	info, err := cli.GetBroadcastListInfo(jid)
+	if err != nil {
+		return err
+	}
+	info.Name = name
+	return cli.Store.BroadcastLists.PutBroadcastList(*info)
+}
+
+// AddBroadcastListRecipients adds the given users to a broadcast list.
+func (cli *Client) AddBroadcastListRecipients(jid types.JID, recipients []types.JID) error {
+	_, err := cli.GetBroadcastListInfo(jid)
+	if err != nil {
+		return err
+	}
+	return cli.Store.BroadcastLists.AddBroadcastListRecipients(jid, recipients)
+}
+
+// RemoveBroadcastListRecipients removes the given users from a broadcast list.
+func (cli *Client) RemoveBroadcastListRecipients(jid types.JID, recipients []types.JID) error {
+	_, err := cli.GetBroadcastListInfo(jid)
+	if err != nil {
+		return err
+	}
+	return cli.Store.BroadcastLists.RemoveBroadcastListRecipients(jid, recipients)
+}
+
+// DeleteBroadcastList deletes the given broadcast list.
+func (cli *Client) DeleteBroadcastList(jid types.JID) error {
+	if !jid.IsBroadcastList() {
+		return ErrNotBroadcastList
+	}
+	return cli.Store.BroadcastLists.DeleteBroadcastList(jid)
+}
@@ -67,6 +67,15 @@ type Client struct {
 	// AutoReconnectHook is called when auto-reconnection fails. If the function returns false,
 	// the client will not attempt to reconnect. The number of retries can be read from AutoReconnectErrors.
 	AutoReconnectHook func(error) bool
+	// ReconnectPolicy decides the delay between automatic reconnection attempts. Defaults to DefaultReconnectPolicy.
+	ReconnectPolicy  ReconnectPolicy
+	autoReconnecting atomic.Bool
+	reconnectNudge   chan struct{}
+
+	// KeepAlive overrides the package-level KeepAlive* variables for this client.
+	KeepAlive         *KeepAliveConfig
+	connHealth        connectionHealthTracker
+	lastFrameReceived atomic.Int64
 
 	sendActiveReceipts atomic.Uint32
 
@@ -81,8 +90,12 @@ type Client struct {
 	appStateProc     *appstate.Processor
 	appStateSyncLock sync.Mutex
 
-	historySyncNotifications  chan *waProto.HistorySyncNotification
+	historySyncNotifications  chan historySyncJob
 	historySyncHandlerStarted atomic.Bool
+	historyRequests           map[types.JID]chan *waProto.HistorySync
+	historyRequestsLock       sync.Mutex
+	historySyncRetries        map[types.MessageID]struct{}
+	historySyncRetriesLock    sync.Mutex
 
 	uploadPreKeysLock sync.Mutex
 	lastPreKeyUpload  time.Time
@@ -143,6 +156,11 @@ type Client struct {
 	// If false, decrypting a message from untrusted devices will fail.
 	AutoTrustIdentity bool
 
+	// Should sending messages to users whose identity was verified (see MarkIdentityTrusted) fail with ErrIdentityChanged
+	// if their identity changes? If true, untrusted identity errors for verified users won't be handled automatically
+	// even if AutoTrustIdentity is enabled, and the identity must be re-verified before messages can be sent.
+	BlockSendToChangedIdentity bool
+
 	// Should sending to own devices be skipped when sending broadcasts?
 	// This works around a bug in the WhatsApp android app where it crashes if you send a status message from a linked device.
 	DontSendSelfBroadcast bool
@@ -150,6 +168,37 @@ type Client struct {
 	// Should SubscribePresence return an error if no privacy token is stored for the user?
 	ErrorOnSubscribePresenceWithoutToken bool
 
+	// Should the content of editable messages be stored so that edits can be validated and their history queried?
+	// This stores every text and captioned media message in the database, so it's disabled by default.
+	// See GetEditHistory and events.MessageEdited.
+	TrackMessageEdits bool
+
+	// Should receipts for messages sent by this client be aggregated into per-message statuses?
+	// See GetMessageStatus and events.MessageStatusChanged.
+	TrackMessageStatus  bool
+	memoryMessageStatus *memoryMessageStatusStore
+
+	// ComposingTimeout is how long a composing chat state is kept in GetTypingUsers without being refreshed.
+	// After the timeout, a ChatPresence event with TimedOut set is dispatched. Defaults to DefaultComposingTimeout.
+	ComposingTimeout time.Duration
+
+	presenceCache             map[types.JID]types.UserPresence
+	typingCache               map[types.JID]map[types.JID]*typingState
+	presenceCacheLock         sync.Mutex
+	presenceSubscriptions     map[types.JID]struct{}
+	issuedPrivacyTokens       map[types.JID]time.Time
+	presenceSubscriptionsLock sync.Mutex
+
+	// DisappearingMessageSweepInterval is how often SweepExpiredMessages is called automatically while connected.
+	// Set to zero to disable the automatic sweep.
+	DisappearingMessageSweepInterval time.Duration
+
+	// Should history sync blobs be decoded one conversation at a time instead of all at once?
+	// When enabled, each conversation is dispatched as a separate events.HistorySyncConversation,
+	// and the final events.HistorySync event won't contain any conversations.
+	// On-demand history syncs (see RequestHistory) are never streamed.
+	StreamHistorySync bool
+
 	phoneLinkingCache *phoneLinkingCache
 
 	uniqueID  string
@@ -216,7 +265,10 @@ func NewClient(deviceStore *store.Device, log waLog.Logger) *Client {
 
 		incomingRetryRequestCounter: make(map[incomingRetryKey]int),
 
-		historySyncNotifications: make(chan *waProto.HistorySyncNotification, 32),
+		reconnectNudge:           make(chan struct{}, 1),
+		historySyncNotifications: make(chan historySyncJob, 32),
+		historyRequests:          make(map[types.JID]chan *waProto.HistorySync),
+		historySyncRetries:       make(map[types.MessageID]struct{}),
 
 		groupParticipantsCache: make(map[types.JID][]types.JID),
 		userDevicesCache:       make(map[types.JID]deviceCache),
@@ -227,10 +279,18 @@ func NewClient(deviceStore *store.Device, log waLog.Logger) *Client {
 		appStateKeyRequests:    make(map[string]time.Time),
 
 		pendingPhoneRerequests: make(map[types.MessageID]context.CancelFunc),
+		memoryMessageStatus:    newMemoryMessageStatusStore(),
+		presenceCache:          make(map[types.JID]types.UserPresence),
+		typingCache:            make(map[types.JID]map[types.JID]*typingState),
+		presenceSubscriptions:  make(map[types.JID]struct{}),
+		issuedPrivacyTokens:    make(map[types.JID]time.Time),
 
 		EnableAutoReconnect:   true,
 		AutoTrustIdentity:     true,
 		DontSendSelfBroadcast: true,
+
+		ComposingTimeout:                 DefaultComposingTimeout,
+		DisappearingMessageSweepInterval: DefaultDisappearingMessageSweepInterval,
 	}
 	cli.nodeHandlers = map[string]nodeHandler{
 		"message":      cli.handleEncryptedMessage,
@@ -356,6 +416,9 @@ func (cli *Client) Connect() error {
 		return fmt.Errorf("noise handshake failed: %w", err)
 	}
 	go cli.keepAliveLoop(cli.socket.Context())
+	go cli.expirySweepLoop(cli.socket.Context())
+	go cli.historySyncRetryLoop(cli.socket.Context())
+	go cli.preKeyMaintenanceLoop(cli.socket.Context())
 	go cli.handlerQueueLoop(cli.socket.Context())
 	return nil
 }
@@ -401,25 +464,56 @@ func (cli *Client) isExpectedDisconnect() bool {
 func (cli *Client) autoReconnect() {
 	if !cli.EnableAutoReconnect || cli.Store.ID == nil {
 		return
+	} else if !cli.autoReconnecting.CompareAndSwap(false, true) {
+		cli.Log.Debugf("Not starting autoreconnect loop as one is already running")
+		return
 	}
+	// Drop any nudges that were sent while the loop wasn't running
+	select {
+	case <-cli.reconnectNudge:
+	default:
+	}
+	policy := cli.getReconnectPolicy()
+	var lastErr error
 	for {
-		autoReconnectDelay := time.Duration(cli.AutoReconnectErrors) * 2 * time.Second
-		cli.Log.Debugf("Automatically reconnecting after %v", autoReconnectDelay)
 		cli.AutoReconnectErrors++
-		time.Sleep(autoReconnectDelay)
+		autoReconnectDelay, ok := policy.NextDelay(cli.AutoReconnectErrors, lastErr)
+		if !ok {
+			cli.Log.Warnf("Reconnect policy gave up after %d attempts", cli.AutoReconnectErrors-1)
+			cli.autoReconnecting.Store(false)
+			cli.dispatchEvent(&events.ReconnectGaveUp{Attempts: cli.AutoReconnectErrors - 1, LastError: lastErr})
+			return
+		}
+		cli.Log.Debugf("Automatically reconnecting after %v", autoReconnectDelay)
+		cli.dispatchEvent(&events.ReconnectAttempt{Attempt: cli.AutoReconnectErrors, Delay: autoReconnectDelay, LastError: lastErr})
+		timer := time.NewTimer(autoReconnectDelay)
+		select {
+		case <-timer.C:
+		case <-cli.reconnectNudge:
+			timer.Stop()
+			cli.Log.Debugf("Got reconnect nudge, skipping remaining autoreconnect sleep")
+		}
 		err := cli.Connect()
 		if errors.Is(err, ErrAlreadyConnected) {
 			cli.Log.Debugf("Connect() said we're already connected after autoreconnect sleep")
-			return
 		} else if err != nil {
 			cli.Log.Errorf("Error reconnecting after autoreconnect sleep: %v", err)
+			lastErr = err
 			if cli.AutoReconnectHook != nil && !cli.AutoReconnectHook(err) {
 				cli.Log.Debugf("AutoReconnectHook returned false, not reconnecting")
+				cli.autoReconnecting.Store(false)
 				return
 			}
-		} else {
-			return
+			continue
+		}
+		cli.autoReconnecting.Store(false)
+		// If the new connection dropped before the flag was cleared, the autoReconnect call from the
+		// disconnect handler will have returned early, so check the connection again here.
+		if !cli.IsConnected() {
+			cli.Log.Debugf("Connection dropped right after autoreconnect, restarting loop")
+			go cli.autoReconnect()
 		}
+		return
 	}
 }
 
@@ -571,6 +665,7 @@ func (cli *Client) RemoveEventHandlers() {
 }
 
 func (cli *Client) handleFrame(data []byte) {
+	cli.markFrameReceived()
 	decompressed, err := waBinary.Unpack(data)
 	if err != nil {
 		cli.Log.Warnf("Failed to decompress frame: %v", err)
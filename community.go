@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+
+	waBinary "github.com/sofyan48/whatsmeow/binary"
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	types "github.com/sofyan48/whatsmeow/types"
+)
+
+// ReqCreateCommunity contains the request data for CreateCommunity.
+type ReqCreateCommunity struct {
+	// Community names are limited to the same length as group names.
+	Name string
+	// Description is optional and will be set after the community is created.
+	Description string
+	// A create key can be provided to deduplicate the group create notification. See ReqCreateGroup for details.
+	CreateKey types.MessageID
+	// Whether new members need admin approval to join the community. Defaults to request_required.
+	DefaultMembershipApprovalMode string
+}
+
+// CreateCommunity creates a new community. The server creates the linked announcement group automatically,
+// its JID is included in the returned CommunityInfo.
+//
+// If the community is created, but setting the description or finding the announcement group fails,
+// the partially filled CommunityInfo is returned along with the error.
+func (cli *Client) CreateCommunity(req ReqCreateCommunity) (*types.CommunityInfo, error) {
+	info, err := cli.CreateGroup(ReqCreateGroup{
+		Name:      req.Name,
+		CreateKey: req.CreateKey,
+		GroupParent: types.GroupParent{
+			IsParent:                      true,
+			DefaultMembershipApprovalMode: req.DefaultMembershipApprovalMode,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	community := &types.CommunityInfo{GroupInfo: *info}
+	if req.Description != "" {
+		err = cli.SetGroupTopic(info.JID, "", "", req.Description)
+		if err != nil {
+			return community, fmt.Errorf("failed to set community description: %w", err)
+		}
+		community.Topic = req.Description
+	}
+	community.AnnouncementGroupJID, err = cli.GetCommunityAnnouncementGroup(info.JID)
+	if err != nil {
+		return community, fmt.Errorf("failed to get announcement group of new community: %w", err)
+	}
+	return community, nil
+}
+
+// DeactivateCommunity deactivates (deletes) a community. The groups in the community are unlinked, but not deleted.
+func (cli *Client) DeactivateCommunity(community types.JID) error {
+	_, err := cli.sendGroupIQ(context.TODO(), iqSet, community, waBinary.Node{Tag: "delete_parent"})
+	return err
+}
+
+// GetCommunityAnnouncementGroup finds the JID of the announcement group (default subgroup) of the given community.
+func (cli *Client) GetCommunityAnnouncementGroup(community types.JID) (types.JID, error) {
+	subGroups, err := cli.GetSubGroups(community)
+	if err != nil {
+		return types.EmptyJID, err
+	}
+	for _, group := range subGroups {
+		if group.IsDefaultSubGroup {
+			return group.JID, nil
+		}
+	}
+	return types.EmptyJID, ErrNoAnnouncementGroup
+}
+
+// GetAdminCommunities gets the info of all communities where the current user is an admin.
+func (cli *Client) GetAdminCommunities() ([]*types.GroupInfo, error) {
+	ownID := cli.getOwnID().ToNonAD()
+	if ownID.IsEmpty() {
+		return nil, ErrNotLoggedIn
+	}
+	groups, err := cli.GetJoinedGroups()
+	if err != nil {
+		return nil, err
+	}
+	var communities []*types.GroupInfo
+	for _, group := range groups {
+		if !group.IsParent {
+			continue
+		}
+		for _, participant := range group.Participants {
+			if participant.JID.User == ownID.User && (participant.IsAdmin || participant.IsSuperAdmin) {
+				communities = append(communities, group)
+				break
+			}
+		}
+	}
+	return communities, nil
+}
+
+// SendCommunityAnnouncement sends a message to the announcement group of the given community.
+//
+// Only community admins can send messages to the announcement group.
+func (cli *Client) SendCommunityAnnouncement(ctx context.Context, community types.JID, message *waProto.Message, extra ...SendRequestExtra) (SendResponse, error) {
+	announcementGroup, err := cli.GetCommunityAnnouncementGroup(community)
+	if err != nil {
+		return SendResponse{}, fmt.Errorf("failed to find announcement group: %w", err)
+	}
+	return cli.SendMessage(ctx, announcementGroup, message, extra...)
+}
+
+// GetCommunityParticipants gets all participants of the given community, including the members of every linked group.
+//
+// The admin flags are based on the community itself, so members of linked groups who aren't community admins
+// will be returned as normal participants.
+func (cli *Client) GetCommunityParticipants(community types.JID) ([]types.GroupParticipant, error) {
+	info, err := cli.GetGroupInfo(community)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get community info: %w", err)
+	}
+	linkedParticipants, err := cli.GetLinkedGroupsParticipants(community)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked group participants: %w", err)
+	}
+	participants := make([]types.GroupParticipant, 0, len(info.Participants)+len(linkedParticipants))
+	seen := make(map[types.JID]struct{}, cap(participants))
+	for _, participant := range info.Participants {
+		seen[participant.JID] = struct{}{}
+		participants = append(participants, participant)
+	}
+	for _, jid := range linkedParticipants {
+		if _, alreadyAdded := seen[jid]; alreadyAdded {
+			continue
+		}
+		seen[jid] = struct{}{}
+		participants = append(participants, types.GroupParticipant{JID: jid})
+	}
+	return participants, nil
+}
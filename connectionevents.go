@@ -7,6 +7,7 @@
 package whatsmeow
 
 import (
+	"context"
 	"time"
 
 	waBinary "github.com/sofyan48/whatsmeow/binary"
@@ -156,6 +157,7 @@ func (cli *Client) handleConnectSuccess(node *waBinary.Node) {
 			cli.Log.Warnf("Failed to get number of prekeys on server: %v", err)
 		} else {
 			cli.Log.Debugf("Database has %d prekeys, server says we have %d", dbCount, serverCount)
+			cli.checkPreKeyDepletion(serverCount)
 			if serverCount < MinPreKeyCount || dbCount < MinPreKeyCount {
 				cli.uploadPreKeys()
 				sc, _ := cli.getServerPreKeyCount()
@@ -168,6 +170,10 @@ func (cli *Client) handleConnectSuccess(node *waBinary.Node) {
 		}
 		cli.dispatchEvent(&events.Connected{})
 		cli.closeSocketWaitChan()
+		cli.resubscribePresences()
+		if cli.Store.PreKeyHealth != nil {
+			cli.maintainPreKeys(context.TODO())
+		}
 	}()
 }
 
@@ -0,0 +1,235 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+// DefaultDisappearingMessageSweepInterval is the default value for Client.DisappearingMessageSweepInterval.
+const DefaultDisappearingMessageSweepInterval = 5 * time.Minute
+
+// getContextInfoField returns a pointer to the ContextInfo field of the main content in the given message,
+// or nil if the message type doesn't have a ContextInfo.
+func getContextInfoField(msg *waProto.Message) **waProto.ContextInfo {
+	switch {
+	case msg.ExtendedTextMessage != nil:
+		return &msg.ExtendedTextMessage.ContextInfo
+	case msg.ImageMessage != nil:
+		return &msg.ImageMessage.ContextInfo
+	case msg.VideoMessage != nil:
+		return &msg.VideoMessage.ContextInfo
+	case msg.AudioMessage != nil:
+		return &msg.AudioMessage.ContextInfo
+	case msg.DocumentMessage != nil:
+		return &msg.DocumentMessage.ContextInfo
+	case msg.StickerMessage != nil:
+		return &msg.StickerMessage.ContextInfo
+	case msg.LocationMessage != nil:
+		return &msg.LocationMessage.ContextInfo
+	case msg.LiveLocationMessage != nil:
+		return &msg.LiveLocationMessage.ContextInfo
+	case msg.ContactMessage != nil:
+		return &msg.ContactMessage.ContextInfo
+	case msg.ContactsArrayMessage != nil:
+		return &msg.ContactsArrayMessage.ContextInfo
+	case msg.PollCreationMessage != nil:
+		return &msg.PollCreationMessage.ContextInfo
+	case msg.PollCreationMessageV3 != nil:
+		return &msg.PollCreationMessageV3.ContextInfo
+	default:
+		return nil
+	}
+}
+
+func (cli *Client) storeDisappearingTimer(chat types.JID, timer time.Duration, settingTS time.Time) {
+	if cli.Store.Disappearing == nil {
+		return
+	}
+	err := cli.Store.Disappearing.PutDisappearingTimer(types.DisappearingTimer{
+		Chat:             chat,
+		Timer:            timer,
+		SettingTimestamp: settingTS,
+	})
+	if err != nil {
+		cli.Log.Warnf("Failed to store disappearing timer of %s: %v", chat, err)
+	} else {
+		cli.Log.Debugf("Stored disappearing timer of %s: %s", chat, timer)
+	}
+}
+
+func (cli *Client) storeGroupDisappearingTimer(group *types.GroupInfo, settingTS time.Time) {
+	var timer time.Duration
+	if group.IsEphemeral {
+		timer = time.Duration(group.DisappearingTimer) * time.Second
+	}
+	cli.storeDisappearingTimer(group.JID, timer, settingTS)
+}
+
+func (cli *Client) handleGroupEphemeralChange(evt *events.GroupInfo) {
+	if evt.Ephemeral == nil {
+		return
+	}
+	var timer time.Duration
+	if evt.Ephemeral.IsEphemeral {
+		timer = time.Duration(evt.Ephemeral.DisappearingTimer) * time.Second
+	}
+	cli.storeDisappearingTimer(evt.JID, timer, evt.Timestamp)
+}
+
+func (cli *Client) handleEphemeralSettingMessage(info *types.MessageInfo, protoMsg *waProto.ProtocolMessage) {
+	settingTS := info.Timestamp
+	if protoMsg.EphemeralSettingTimestamp != nil {
+		settingTS = time.Unix(protoMsg.GetEphemeralSettingTimestamp(), 0)
+	}
+	cli.storeDisappearingTimer(info.Chat, time.Duration(protoMsg.GetEphemeralExpiration())*time.Second, settingTS)
+}
+
+func (cli *Client) storeHistoricalDisappearingTimers(conversations []*waProto.Conversation) {
+	if cli.Store.Disappearing == nil {
+		return
+	}
+	for _, conv := range conversations {
+		chatJID, _ := types.ParseJID(conv.GetId())
+		if chatJID.IsEmpty() || conv.EphemeralExpiration == nil {
+			continue
+		}
+		cli.storeDisappearingTimer(
+			chatJID,
+			time.Duration(conv.GetEphemeralExpiration())*time.Second,
+			time.Unix(conv.GetEphemeralSettingTimestamp(), 0),
+		)
+	}
+}
+
+func (cli *Client) trackMessageExpiration(evt *events.Message) {
+	if cli.Store.Disappearing == nil || evt.IsEdit || evt.Message.ProtocolMessage != nil {
+		return
+	}
+	var expiration time.Duration
+	if ctxInfo := getContextInfoField(evt.Message); ctxInfo != nil && (*ctxInfo).GetExpiration() > 0 {
+		expiration = time.Duration((*ctxInfo).GetExpiration()) * time.Second
+	} else if evt.IsEphemeral {
+		timer, err := cli.Store.Disappearing.GetDisappearingTimer(evt.Info.Chat)
+		if err != nil {
+			cli.Log.Warnf("Failed to get disappearing timer of %s: %v", evt.Info.Chat, err)
+			return
+		}
+		expiration = timer.Timer
+	}
+	if expiration <= 0 {
+		return
+	}
+	err := cli.Store.Disappearing.PutMessageExpiration(types.ExpiringMessage{
+		Chat:      evt.Info.Chat,
+		ID:        evt.Info.ID,
+		ExpiresAt: evt.Info.Timestamp.Add(expiration),
+	})
+	if err != nil {
+		cli.Log.Warnf("Failed to store expiration of %s: %v", evt.Info.ID, err)
+	}
+}
+
+// applyDisappearingTimer sets ContextInfo.Expiration in the given message if the chat has a disappearing timer.
+// The message is cloned before modifying it, and messages that already have an expiration set are not touched.
+// Plain text messages are converted to extended text messages, as they can't have a ContextInfo.
+func (cli *Client) applyDisappearingTimer(to types.JID, message *waProto.Message) *waProto.Message {
+	existing := getContextInfoField(message)
+	isPlainText := existing == nil && message.Conversation != nil
+	if cli.Store.Disappearing == nil || message.ProtocolMessage != nil || (existing == nil && !isPlainText) {
+		return message
+	} else if existing != nil && *existing != nil && (*existing).Expiration != nil {
+		return message
+	}
+	timer, err := cli.Store.Disappearing.GetDisappearingTimer(to)
+	if err != nil {
+		cli.Log.Warnf("Failed to get disappearing timer of %s: %v", to, err)
+		return message
+	} else if timer.Timer <= 0 {
+		return message
+	}
+	message = proto.Clone(message).(*waProto.Message)
+	if isPlainText {
+		message.ExtendedTextMessage = &waProto.ExtendedTextMessage{Text: message.Conversation}
+		message.Conversation = nil
+	}
+	ctxInfo := getContextInfoField(message)
+	if *ctxInfo == nil {
+		*ctxInfo = &waProto.ContextInfo{}
+	}
+	(*ctxInfo).Expiration = proto.Uint32(uint32(timer.Timer.Seconds()))
+	if !timer.SettingTimestamp.IsZero() {
+		(*ctxInfo).EphemeralSettingTimestamp = proto.Int64(timer.SettingTimestamp.Unix())
+	}
+	return message
+}
+
+// GetDisappearingTimer returns the disappearing message timer of the given chat.
+//
+// Timers are tracked automatically from group info, disappearing setting changes and history syncs.
+// Zero is returned if disappearing messages are disabled or the timer isn't known.
+func (cli *Client) GetDisappearingTimer(chat types.JID) (time.Duration, error) {
+	if cli.Store.Disappearing == nil {
+		return 0, fmt.Errorf("disappearing timer store is not available")
+	}
+	timer, err := cli.Store.Disappearing.GetDisappearingTimer(chat)
+	return timer.Timer, err
+}
+
+// SweepExpiredMessages deletes the locally stored data of disappearing messages that have expired,
+// and dispatches an *events.MessagesExpired event with the list of expired messages, so that
+// applications can delete their own copies too.
+//
+// This is called automatically every DisappearingMessageSweepInterval while the client is connected.
+func (cli *Client) SweepExpiredMessages() ([]types.ExpiringMessage, error) {
+	if cli.Store.Disappearing == nil {
+		return nil, fmt.Errorf("disappearing timer store is not available")
+	}
+	expired, err := cli.Store.Disappearing.DeleteExpiredMessages(time.Now())
+	if err != nil {
+		return nil, err
+	} else if len(expired) > 0 {
+		if cli.Store.MessageSearch != nil {
+			for _, msg := range expired {
+				err = cli.Store.MessageSearch.DeleteIndexedMessage(msg.Chat, msg.ID)
+				if err != nil {
+					cli.Log.Warnf("Failed to remove expired message %s from search index: %v", msg.ID, err)
+				}
+			}
+		}
+		cli.Log.Debugf("Deleted data of %d expired disappearing messages", len(expired))
+		cli.dispatchEvent(&events.MessagesExpired{Messages: expired})
+	}
+	return expired, nil
+}
+
+func (cli *Client) expirySweepLoop(ctx context.Context) {
+	if cli.DisappearingMessageSweepInterval <= 0 || cli.Store.Disappearing == nil {
+		return
+	}
+	ticker := time.NewTicker(cli.DisappearingMessageSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_, err := cli.SweepExpiredMessages()
+			if err != nil {
+				cli.Log.Warnf("Failed to sweep expired disappearing messages: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
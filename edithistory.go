@@ -0,0 +1,141 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"fmt"
+	"time"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+func isEditableMessage(msg *waProto.Message) bool {
+	return msg.Conversation != nil ||
+		msg.ExtendedTextMessage != nil ||
+		msg.ImageMessage != nil ||
+		msg.VideoMessage != nil ||
+		msg.DocumentMessage != nil
+}
+
+// trackEdits stores edit history entries and dispatches MessageEdited events.
+func (cli *Client) trackEdits(evt *events.Message) {
+	if !cli.TrackMessageEdits || cli.Store.EditHistory == nil {
+		return
+	}
+	protoMsg := evt.Message.GetProtocolMessage()
+	if evt.IsEdit && protoMsg.GetType() == waProto.ProtocolMessage_MESSAGE_EDIT {
+		cli.storeMessageEdit(evt, protoMsg)
+	} else if !evt.IsEdit && isEditableMessage(evt.Message) {
+		err := cli.Store.EditHistory.PutMessageVersion(types.MessageVersion{
+			Chat:    evt.Info.Chat,
+			ID:      evt.Info.ID,
+			Sender:  evt.Info.Sender,
+			EditTS:  evt.Info.Timestamp,
+			Content: evt.Message,
+		})
+		if err != nil {
+			cli.Log.Warnf("Failed to store original version of %s from %s: %v", evt.Info.ID, evt.Info.SourceString(), err)
+		}
+	}
+}
+
+func (cli *Client) storeMessageEdit(evt *events.Message, protoMsg *waProto.ProtocolMessage) {
+	origID := types.MessageID(protoMsg.GetKey().GetId())
+	version := types.MessageVersion{
+		Chat:    evt.Info.Chat,
+		ID:      origID,
+		Sender:  evt.Info.Sender,
+		EditID:  evt.Info.ID,
+		EditTS:  evt.Info.Timestamp,
+		Content: protoMsg.GetEditedMessage(),
+	}
+	if protoMsg.TimestampMs != nil {
+		version.EditTS = time.UnixMilli(protoMsg.GetTimestampMs())
+	}
+	if version.EditID == origID {
+		// Edits sent by this client reuse the original message ID, so use the edit timestamp to keep versions unique.
+		version.EditID = fmt.Sprintf("%s-%d", origID, version.EditTS.UnixMilli())
+	}
+	versions, err := cli.Store.EditHistory.GetMessageVersions(evt.Info.Chat, origID)
+	if err != nil {
+		cli.Log.Warnf("Failed to get edit history of %s: %v", origID, err)
+		return
+	}
+	versions, original := filterMessageVersions(versions)
+	if original != nil {
+		err = validateMessageEdit(original, &version)
+		if err != nil {
+			cli.Log.Warnf("Ignoring edit %s from %s to %s: %v", evt.Info.ID, evt.Info.SourceString(), origID, err)
+			return
+		}
+	}
+	err = cli.Store.EditHistory.PutMessageVersion(version)
+	if err != nil {
+		cli.Log.Warnf("Failed to store edit %s from %s to %s: %v", evt.Info.ID, evt.Info.SourceString(), origID, err)
+		return
+	} else if original == nil {
+		// Without the original, the sender and edit window can't be validated, so don't report the edit
+		cli.Log.Debugf("Stored edit %s from %s to %s without dispatching event, original message isn't known", evt.Info.ID, evt.Info.SourceString(), origID)
+		return
+	}
+	cli.dispatchEvent(&events.MessageEdited{
+		Info:       evt.Info,
+		OriginalID: origID,
+		EditTS:     version.EditTS,
+		OldContent: versions[len(versions)-1].Content,
+		NewContent: version.Content,
+	})
+}
+
+// filterMessageVersions finds the original version in the given list and removes edits that aren't valid for it.
+// If the original version isn't known, the edits can't be validated and the list is returned as-is.
+func filterMessageVersions(versions []types.MessageVersion) ([]types.MessageVersion, *types.MessageVersion) {
+	var original *types.MessageVersion
+	for i := range versions {
+		if versions[i].EditID == "" {
+			original = &versions[i]
+			break
+		}
+	}
+	if original == nil {
+		return versions, nil
+	}
+	filtered := []types.MessageVersion{*original}
+	for _, version := range versions {
+		if version.EditID != "" && validateMessageEdit(original, &version) == nil {
+			filtered = append(filtered, version)
+		}
+	}
+	return filtered, &filtered[0]
+}
+
+func validateMessageEdit(original, edit *types.MessageVersion) error {
+	if original.Sender.ToNonAD() != edit.Sender.ToNonAD() {
+		return fmt.Errorf("%w (original sent by %s, edited by %s)", ErrEditSenderMismatch, original.Sender, edit.Sender)
+	} else if edit.EditTS.Sub(original.EditTS) > EditWindow {
+		return fmt.Errorf("%w (original sent at %s, edited at %s)", ErrEditWindowExpired, original.EditTS, edit.EditTS)
+	}
+	return nil
+}
+
+// GetEditHistory returns all known versions of the given message, starting with the original version.
+//
+// The history is only recorded if TrackMessageEdits is enabled. If the original message was received before
+// tracking was enabled, the first returned version will be the first edit, and the edits couldn't be validated.
+func (cli *Client) GetEditHistory(chat types.JID, id types.MessageID) ([]types.MessageVersion, error) {
+	if cli.Store.EditHistory == nil {
+		return nil, fmt.Errorf("edit history store is not available")
+	}
+	versions, err := cli.Store.EditHistory.GetMessageVersions(chat, id)
+	if err != nil {
+		return nil, err
+	}
+	versions, _ = filterMessageVersions(versions)
+	return versions, nil
+}
@@ -98,11 +98,38 @@ var (
 	ErrUnknownMediaRetryError = errors.New("unknown media retry error")
 	// ErrInvalidDisappearingTimer is returned by SetDisappearingTimer if the given timer is not one of the allowed values.
 	ErrInvalidDisappearingTimer = errors.New("invalid disappearing timer provided")
+	// ErrBroadcastListNotFound is returned by broadcast list methods if the list isn't in the device store.
+	ErrBroadcastListNotFound = errors.New("that broadcast list does not exist")
+	// ErrNotBroadcastList is returned by broadcast list methods if the given JID isn't a broadcast list JID.
+	ErrNotBroadcastList = errors.New("that JID is not a broadcast list")
+	// ErrBroadcastListEmpty is returned by CreateBroadcastList if no recipients are given.
+	ErrBroadcastListEmpty = errors.New("broadcast lists must have at least one recipient")
+	// ErrNewsletterMessageNotFound is returned by GetNewsletterMessageStats if the server didn't return the requested message.
+	ErrNewsletterMessageNotFound = errors.New("that newsletter message does not exist")
+	// ErrNotNewsletterPoll is returned by GetNewsletterPollResults if the given message isn't a poll.
+	ErrNotNewsletterPoll = errors.New("that newsletter message is not a poll")
+	// ErrNoAnnouncementGroup is returned by community methods if the community doesn't have an announcement group.
+	ErrNoAnnouncementGroup = errors.New("that community does not have an announcement group")
+	// ErrEditSenderMismatch is logged when an edit is ignored because it wasn't sent by the sender of the original message.
+	ErrEditSenderMismatch = errors.New("edit sender doesn't match original message sender")
+	// ErrEditWindowExpired is logged when an edit is ignored because it was sent more than EditWindow after the original message.
+	ErrEditWindowExpired = errors.New("edit was sent after the edit window expired")
+	// ErrHistoryRequestInProgress is returned by Client.RequestHistory if there's already a pending history request for the same chat.
+	ErrHistoryRequestInProgress = errors.New("there's already a pending history request for that chat")
+	// ErrHistoryRequestTimedOut is returned by Client.RequestHistory if the phone didn't respond within HistoryRequestTimeout.
+	ErrHistoryRequestTimedOut = errors.New("timed out waiting for history sync response from phone")
+	// ErrNotOwnDevice is returned by Client.RemoveLinkedDevice if the given JID isn't a device of the user's own account.
+	ErrNotOwnDevice = errors.New("that device doesn't belong to the user's own account")
+	// ErrCannotRemovePrimaryDevice is returned by Client.RemoveLinkedDevice if the given JID is the primary device.
+	ErrCannotRemovePrimaryDevice = errors.New("the primary device can't be unlinked")
+	// ErrIdentityChanged is returned by Client.SendMessage (and used as the decryption error of incoming messages)
+	// if Client.BlockSendToChangedIdentity is enabled and the other user's identity has changed since it was verified.
+	ErrIdentityChanged = errors.New("identity has changed since it was verified")
 )
 
 // Some errors that Client.SendMessage can return
 var (
-	ErrBroadcastListUnsupported = errors.New("sending to non-status broadcast lists is not yet supported")
+	ErrBroadcastListUnsupported = errors.New("sending to that broadcast JID is not supported")
 	ErrUnknownServer            = errors.New("can't send message to unknown server")
 	ErrRecipientADJID           = errors.New("message recipient must be a user JID with no device part")
 	ErrServerReturnedError      = errors.New("server returned error")
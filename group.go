@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	waBinary "github.com/sofyan48/whatsmeow/binary"
 	types "github.com/sofyan48/whatsmeow/types"
@@ -338,6 +339,43 @@ func (cli *Client) SetGroupAnnounce(jid types.JID, announce bool) error {
 	return err
 }
 
+// SetGroupMemberAddMode changes who can add new members to the group.
+func (cli *Client) SetGroupMemberAddMode(jid types.JID, mode types.GroupMemberAddMode) error {
+	_, err := cli.sendGroupIQ(context.TODO(), iqSet, jid, waBinary.Node{
+		Tag:     "member_add_mode",
+		Content: []byte(mode),
+	})
+	return err
+}
+
+// SetGroupJoinApprovalMode changes whether admins must approve new members who join via invite link.
+//
+// Pending requests can be fetched with GetGroupRequestParticipants.
+func (cli *Client) SetGroupJoinApprovalMode(jid types.JID, required bool) error {
+	state := "off"
+	if required {
+		state = "on"
+	}
+	_, err := cli.sendGroupIQ(context.TODO(), iqSet, jid, waBinary.Node{
+		Tag: "membership_approval_mode",
+		Content: []waBinary.Node{{
+			Tag:   "group_join",
+			Attrs: waBinary.Attrs{"state": state},
+		}},
+	})
+	return err
+}
+
+// SetCommunityAllowNonAdminSubGroupCreation changes whether non-admin members of a community can add new groups to it.
+func (cli *Client) SetCommunityAllowNonAdminSubGroupCreation(jid types.JID, allow bool) error {
+	tag := "allow_non_admin_sub_group_creation"
+	if !allow {
+		tag = "not_allow_non_admin_sub_group_creation"
+	}
+	_, err := cli.sendGroupIQ(context.TODO(), iqSet, jid, waBinary.Node{Tag: tag})
+	return err
+}
+
 // GetGroupInviteLink requests the invite link to the group from the WhatsApp servers.
 //
 // If reset is true, then the old invite link will be revoked and a new one generated.
@@ -540,6 +578,7 @@ func (cli *Client) getGroupInfo(ctx context.Context, jid types.JID, lockParticip
 	if err != nil {
 		return groupInfo, err
 	}
+	cli.storeGroupDisappearingTimer(groupInfo, time.Now())
 	if lockParticipantCache {
 		cli.groupParticipantsCacheLock.Lock()
 		defer cli.groupParticipantsCacheLock.Unlock()
@@ -640,7 +679,10 @@ func (cli *Client) parseGroupNode(groupNode *waBinary.Node) (*types.GroupInfo, e
 			group.DefaultMembershipApprovalMode = childAG.OptionalString("default_membership_approval_mode")
 		case "incognito":
 			group.IsIncognito = true
-		// TODO: membership_approval_mode
+		case "membership_approval_mode":
+			group.IsJoinApprovalRequired = parseMembershipApprovalMode(&child)
+		case "allow_non_admin_sub_group_creation":
+			group.AllowNonAdminSubGroupCreation = true
 		default:
 			cli.Log.Debugf("Unknown element in group node %s: %s", group.JID.String(), child.XMLString())
 		}
@@ -670,6 +712,11 @@ func parseGroupLinkTargetNode(groupNode *waBinary.Node) (types.GroupLinkTarget,
 	}, ag.Error()
 }
 
+func parseMembershipApprovalMode(node *waBinary.Node) bool {
+	groupJoin, ok := node.GetOptionalChildByTag("group_join")
+	return ok && groupJoin.AttrGetter().OptionalString("state") == "on"
+}
+
 func parseParticipantList(node *waBinary.Node) (participants []types.JID) {
 	children := node.GetChildren()
 	participants = make([]types.JID, 0, len(children))
@@ -698,6 +745,7 @@ func (cli *Client) parseGroupCreate(node *waBinary.Node) (*events.JoinedGroup, e
 		return nil, fmt.Errorf("failed to parse group info in create notification: %w", err)
 	}
 	evt.GroupInfo = *info
+	cli.storeGroupDisappearingTimer(info, time.Now())
 	return &evt, nil
 }
 
@@ -783,6 +831,18 @@ func (cli *Client) parseGroupChange(node *waBinary.Node) (*events.GroupInfo, err
 			}
 		case "not_ephemeral":
 			evt.Ephemeral = &types.GroupEphemeral{IsEphemeral: false}
+		case "member_add_mode":
+			modeBytes, _ := child.Content.([]byte)
+			mode := types.GroupMemberAddMode(modeBytes)
+			evt.MemberAddMode = &mode
+		case "membership_approval_mode":
+			evt.MembershipApprovalMode = &types.GroupMembershipApprovalMode{
+				IsJoinApprovalRequired: parseMembershipApprovalMode(&child),
+			}
+		case "allow_non_admin_sub_group_creation":
+			evt.CommunitySettings = &types.GroupCommunitySettings{AllowNonAdminSubGroupCreation: true}
+		case "not_allow_non_admin_sub_group_creation":
+			evt.CommunitySettings = &types.GroupCommunitySettings{AllowNonAdminSubGroupCreation: false}
 		case "link":
 			evt.Link = &types.GroupLinkChange{
 				Type: types.GroupLinkChangeType(cag.String("link_type")),
@@ -861,6 +921,7 @@ func (cli *Client) parseGroupNotification(node *waBinary.Node) (interface{}, err
 			return nil, err
 		}
 		cli.updateGroupParticipantCache(groupChange)
+		cli.handleGroupEphemeralChange(groupChange)
 		return groupChange, nil
 	}
 }
@@ -0,0 +1,126 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+// HistoryRequestTimeout is the maximum time Client.RequestHistory will wait for the phone to respond.
+var HistoryRequestTimeout = 2 * time.Minute
+
+func (cli *Client) handleOnDemandHistorySync(historySync *waProto.HistorySync) {
+	cli.historyRequestsLock.Lock()
+	defer cli.historyRequestsLock.Unlock()
+	if len(cli.historyRequests) == 0 {
+		return
+	}
+	delivered := false
+	for _, conv := range historySync.GetConversations() {
+		chatJID, err := types.ParseJID(conv.GetId())
+		if err != nil {
+			continue
+		}
+		if ch, ok := cli.historyRequests[chatJID]; ok {
+			ch <- historySync
+			delete(cli.historyRequests, chatJID)
+			delivered = true
+		}
+	}
+	if !delivered && len(historySync.GetConversations()) == 0 && len(cli.historyRequests) == 1 {
+		// The phone sends an empty response when there are no more messages in the chat,
+		// which can only be matched to a request if there's just one pending.
+		for chatJID, ch := range cli.historyRequests {
+			ch <- historySync
+			delete(cli.historyRequests, chatJID)
+		}
+	}
+}
+
+func (cli *Client) parseOnDemandHistorySync(chat types.JID, historySync *waProto.HistorySync) []*events.Message {
+	var messages []*events.Message
+	for _, conv := range historySync.GetConversations() {
+		convJID, err := types.ParseJID(conv.GetId())
+		if err != nil || convJID != chat {
+			continue
+		}
+		for _, historyMsg := range conv.GetMessages() {
+			evt, err := cli.ParseWebMessage(chat, historyMsg.GetMessage())
+			if err != nil {
+				cli.Log.Warnf("Failed to parse message %s in on-demand history sync for %s: %v", historyMsg.GetMessage().GetKey().GetId(), chat, err)
+				continue
+			}
+			messages = append(messages, evt)
+		}
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Info.Timestamp.Before(messages[j].Info.Timestamp)
+	})
+	return messages
+}
+
+// RequestHistory requests up to count messages sent in the given chat before the given message from the user's primary device.
+//
+// The returned messages are sorted from oldest to newest. To page further backwards, call RequestHistory again with
+// the info of the first (oldest) returned message. An empty list means there are no more messages available on the phone.
+//
+//	messages, err := cli.RequestHistory(ctx, chat, oldestKnownMessage.Info, 50)
+//	for len(messages) > 0 && err == nil {
+//		handleOldMessages(messages)
+//		messages, err = cli.RequestHistory(ctx, chat, messages[0].Info, 50)
+//	}
+//
+// The response is still emitted as a normal *events.HistorySync too. Only one request per chat can be in progress at a time.
+// If the phone doesn't respond within HistoryRequestTimeout, ErrHistoryRequestTimedOut is returned.
+func (cli *Client) RequestHistory(ctx context.Context, chat types.JID, before types.MessageInfo, count int) ([]*events.Message, error) {
+	ownID := cli.getOwnID().ToNonAD()
+	if ownID.IsEmpty() {
+		return nil, ErrNotLoggedIn
+	}
+	chat = chat.ToNonAD()
+	if before.Chat.IsEmpty() {
+		before.Chat = chat
+	} else if before.Chat.ToNonAD() != chat {
+		return nil, fmt.Errorf("message %s is not in %s", before.ID, chat)
+	}
+
+	ch := make(chan *waProto.HistorySync, 1)
+	cli.historyRequestsLock.Lock()
+	if _, alreadyRequesting := cli.historyRequests[chat]; alreadyRequesting {
+		cli.historyRequestsLock.Unlock()
+		return nil, ErrHistoryRequestInProgress
+	}
+	cli.historyRequests[chat] = ch
+	cli.historyRequestsLock.Unlock()
+	defer func() {
+		cli.historyRequestsLock.Lock()
+		if cli.historyRequests[chat] == ch {
+			delete(cli.historyRequests, chat)
+		}
+		cli.historyRequestsLock.Unlock()
+	}()
+
+	_, err := cli.SendMessage(ctx, ownID, cli.BuildHistorySyncRequest(&before, count), SendRequestExtra{Peer: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send history sync request: %w", err)
+	}
+	select {
+	case historySync := <-ch:
+		return cli.parseOnDemandHistorySync(chat, historySync), nil
+	case <-time.After(HistoryRequestTimeout):
+		return nil, ErrHistoryRequestTimedOut
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
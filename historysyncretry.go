@@ -0,0 +1,276 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/store"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+var (
+	// HistorySyncRetryBaseDelay is the delay before the first retry of a failed history sync download.
+	// The delay is doubled after every failed attempt.
+	HistorySyncRetryBaseDelay = 1 * time.Minute
+	// HistorySyncRetryMaxDelay is the maximum delay between history sync download retries.
+	HistorySyncRetryMaxDelay = 6 * time.Hour
+	// HistorySyncMaxAttempts is the number of download attempts after which failed history syncs aren't retried automatically.
+	HistorySyncMaxAttempts = 10
+	// historySyncRetryCheckInterval is how often the retry loop checks for failed history syncs that should be retried.
+	historySyncRetryCheckInterval = 1 * time.Minute
+)
+
+type historySyncJob struct {
+	info    types.MessageInfo
+	notif   *waProto.HistorySyncNotification
+	isRetry bool
+	// The number of conversations already dispatched by previous attempts to stream this history sync.
+	streamedConversations int
+}
+
+func (cli *Client) enqueueHistorySync(job historySyncJob) {
+	cli.historySyncNotifications <- job
+	if cli.historySyncHandlerStarted.CompareAndSwap(false, true) {
+		go cli.handleHistorySyncNotificationLoop()
+	}
+}
+
+// enqueueHistorySyncRetry enqueues a retry of the given failed history sync,
+// unless a retry of the same history sync is already queued or in progress.
+func (cli *Client) enqueueHistorySyncRetry(failure *store.FailedHistorySync) bool {
+	cli.historySyncRetriesLock.Lock()
+	_, inProgress := cli.historySyncRetries[failure.MessageID]
+	if !inProgress {
+		cli.historySyncRetries[failure.MessageID] = struct{}{}
+	}
+	cli.historySyncRetriesLock.Unlock()
+	if inProgress {
+		return false
+	}
+	cli.enqueueHistorySync(failureToJob(failure))
+	return true
+}
+
+func (cli *Client) finishHistorySyncRetry(id types.MessageID) {
+	cli.historySyncRetriesLock.Lock()
+	delete(cli.historySyncRetries, id)
+	cli.historySyncRetriesLock.Unlock()
+}
+
+func historySyncRetryDelay(attempts int) time.Duration {
+	delay := HistorySyncRetryBaseDelay
+	for i := 1; i < attempts && delay < HistorySyncRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > HistorySyncRetryMaxDelay {
+		delay = HistorySyncRetryMaxDelay
+	}
+	return delay
+}
+
+func isExpiredMediaError(err error) bool {
+	return errors.Is(err, ErrMediaDownloadFailedWith403) ||
+		errors.Is(err, ErrMediaDownloadFailedWith404) ||
+		errors.Is(err, ErrMediaDownloadFailedWith410)
+}
+
+func (cli *Client) handleHistorySyncFailure(job historySyncJob, err error) {
+	cli.Log.Errorf("Failed to process history sync %s (type %s, chunk %d): %v", job.info.ID, job.notif.GetSyncType(), job.notif.GetChunkOrder(), err)
+	failure := store.FailedHistorySync{
+		MessageID:    job.info.ID,
+		Chat:         job.info.Chat,
+		Sender:       job.info.Sender,
+		Notification: job.notif,
+		Attempts:     1,
+		LastError:    err.Error(),
+		FailedAt:     time.Now(),
+
+		StreamedConversations: job.streamedConversations,
+	}
+	if cli.Store.HistorySyncs != nil {
+		existing, getErr := cli.Store.HistorySyncs.GetFailedHistorySync(job.info.ID)
+		if getErr != nil {
+			cli.Log.Warnf("Failed to get previous failures of history sync %s: %v", job.info.ID, getErr)
+		} else if existing != nil {
+			failure.Attempts = existing.Attempts + 1
+			failure.FailedAt = existing.FailedAt
+			// Keep the stored notification, as it may have gotten a new direct path from a media retry during the download
+			failure.Notification = existing.Notification
+			if existing.StreamedConversations > failure.StreamedConversations {
+				failure.StreamedConversations = existing.StreamedConversations
+			}
+		}
+		if failure.Attempts < HistorySyncMaxAttempts {
+			failure.NextRetry = time.Now().Add(historySyncRetryDelay(failure.Attempts))
+		}
+		putErr := cli.Store.HistorySyncs.PutFailedHistorySync(failure)
+		if putErr != nil {
+			cli.Log.Errorf("Failed to save failed history sync %s: %v", job.info.ID, putErr)
+			failure.NextRetry = time.Time{}
+		}
+	}
+	if isExpiredMediaError(err) && job.info.ID != "" {
+		// The phone can re-upload expired history sync blobs, the new path will come in a media retry notification.
+		cli.Log.Debugf("Requesting re-upload of history sync %s", job.info.ID)
+		retryErr := cli.SendMediaRetryReceipt(&job.info, job.notif.GetMediaKey())
+		if retryErr != nil {
+			cli.Log.Warnf("Failed to request re-upload of history sync %s: %v", job.info.ID, retryErr)
+		}
+	}
+	cli.dispatchEvent(&events.HistorySyncFailed{
+		MessageID:  job.info.ID,
+		SyncType:   job.notif.GetSyncType(),
+		ChunkOrder: job.notif.GetChunkOrder(),
+		Error:      err,
+		Attempts:   failure.Attempts,
+		NextRetry:  failure.NextRetry,
+	})
+}
+
+func (cli *Client) clearHistorySyncFailure(id types.MessageID) {
+	if cli.Store.HistorySyncs == nil {
+		return
+	}
+	err := cli.Store.HistorySyncs.DeleteFailedHistorySync(id)
+	if err != nil {
+		cli.Log.Warnf("Failed to delete history sync %s from failed list after successful retry: %v", id, err)
+	}
+}
+
+func failureToJob(failure *store.FailedHistorySync) historySyncJob {
+	return historySyncJob{
+		info: types.MessageInfo{
+			MessageSource: types.MessageSource{
+				Chat:     failure.Chat,
+				Sender:   failure.Sender,
+				IsFromMe: true,
+			},
+			ID: failure.MessageID,
+		},
+		notif:   failure.Notification,
+		isRetry: true,
+
+		streamedConversations: failure.StreamedConversations,
+	}
+}
+
+func (cli *Client) retryFailedHistorySyncs() {
+	failures, err := cli.Store.HistorySyncs.GetFailedHistorySyncs()
+	if err != nil {
+		cli.Log.Warnf("Failed to get failed history syncs: %v", err)
+		return
+	}
+	now := time.Now()
+	for i := range failures {
+		failure := &failures[i]
+		if failure.NextRetry.IsZero() || failure.NextRetry.After(now) {
+			continue
+		}
+		// Push the next retry forward before enqueueing, so that the same failure isn't retried again
+		// if the download takes longer than the check interval or the client restarts mid-download.
+		failure.NextRetry = now.Add(historySyncRetryDelay(failure.Attempts + 1))
+		err = cli.Store.HistorySyncs.PutFailedHistorySync(*failure)
+		if err != nil {
+			cli.Log.Warnf("Failed to update next retry time of history sync %s: %v", failure.MessageID, err)
+			continue
+		}
+		if cli.enqueueHistorySyncRetry(failure) {
+			cli.Log.Debugf("Retrying failed history sync %s (attempt #%d)", failure.MessageID, failure.Attempts+1)
+		}
+	}
+}
+
+func (cli *Client) historySyncRetryLoop(ctx context.Context) {
+	if cli.Store.HistorySyncs == nil {
+		return
+	}
+	ticker := time.NewTicker(historySyncRetryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			cli.retryFailedHistorySyncs()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleHistorySyncMediaRetry checks if the given media retry notification is for a failed history sync,
+// and if so, retries the download with the new direct path.
+func (cli *Client) handleHistorySyncMediaRetry(evt *events.MediaRetry) {
+	if cli.Store.HistorySyncs == nil {
+		return
+	}
+	failure, err := cli.Store.HistorySyncs.GetFailedHistorySync(evt.MessageID)
+	if err != nil {
+		cli.Log.Warnf("Failed to check if media retry %s is for a history sync: %v", evt.MessageID, err)
+		return
+	} else if failure == nil {
+		return
+	}
+	retryData, err := DecryptMediaRetryNotification(evt, failure.Notification.GetMediaKey())
+	if err != nil {
+		cli.Log.Warnf("Failed to decrypt media retry for history sync %s: %v", evt.MessageID, err)
+		return
+	} else if retryData.GetResult() != waProto.MediaRetryNotification_SUCCESS || retryData.GetDirectPath() == "" {
+		cli.Log.Warnf("Phone couldn't re-upload history sync %s (result: %s)", evt.MessageID, retryData.GetResult())
+		return
+	}
+	failure.Notification.DirectPath = retryData.DirectPath
+	err = cli.Store.HistorySyncs.PutFailedHistorySync(*failure)
+	if err != nil {
+		cli.Log.Warnf("Failed to save new direct path of history sync %s: %v", evt.MessageID, err)
+	}
+	if cli.enqueueHistorySyncRetry(failure) {
+		cli.Log.Debugf("Got new direct path for history sync %s, retrying download", evt.MessageID)
+	} else {
+		cli.Log.Debugf("Got new direct path for history sync %s, but a retry is already in progress", evt.MessageID)
+	}
+}
+
+// GetMissingHistorySyncChunks returns the history sync chunks that couldn't be downloaded or decoded,
+// sorted by sync type and chunk order.
+//
+// Failed chunks are retried automatically with exponential backoff (see HistorySyncRetryBaseDelay),
+// and chunks whose download URL has expired are re-requested from the phone. Chunks are removed from
+// the list once they're processed successfully.
+func (cli *Client) GetMissingHistorySyncChunks() ([]types.MissingHistorySyncChunk, error) {
+	if cli.Store.HistorySyncs == nil {
+		return nil, fmt.Errorf("history sync failure store is not available")
+	}
+	failures, err := cli.Store.HistorySyncs.GetFailedHistorySyncs()
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]types.MissingHistorySyncChunk, len(failures))
+	for i, failure := range failures {
+		chunks[i] = types.MissingHistorySyncChunk{
+			MessageID:  failure.MessageID,
+			SyncType:   failure.Notification.GetSyncType(),
+			ChunkOrder: failure.Notification.GetChunkOrder(),
+			Attempts:   failure.Attempts,
+			LastError:  failure.LastError,
+			FailedAt:   failure.FailedAt,
+			NextRetry:  failure.NextRetry,
+		}
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i].SyncType != chunks[j].SyncType {
+			return chunks[i].SyncType < chunks[j].SyncType
+		}
+		return chunks[i].ChunkOrder < chunks[j].ChunkOrder
+	})
+	return chunks, nil
+}
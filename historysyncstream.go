@@ -0,0 +1,150 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+// MaxStreamedHistorySyncFieldSize is the maximum size of a single field (e.g. one conversation)
+// that DecodeHistorySyncStream will read into memory. Chunks with larger conversations fail to decode.
+//
+// Note that only decoding is streamed: the compressed blob is still downloaded into memory in full.
+var MaxStreamedHistorySyncFieldSize uint64 = 16 * 1024 * 1024
+
+// historySyncConversationsField is the field number of HistorySync.conversations
+const historySyncConversationsField protowire.Number = 2
+
+// DecodeHistorySyncStream decodes a decompressed HistorySync protobuf from the given reader without
+// reading the whole message into memory.
+//
+// The callback is called for each conversation as soon as it has been decoded. All other fields are
+// collected into the returned HistorySync, which won't have any conversations.
+// If the callback returns an error, decoding is stopped and the error is returned.
+func DecodeHistorySyncStream(r io.Reader, fn func(conv *waProto.Conversation) error) (*waProto.HistorySync, error) {
+	reader := bufio.NewReader(r)
+	var rest []byte
+	for {
+		rawTag, err := binary.ReadUvarint(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read field tag: %w", err)
+		}
+		num, typ := protowire.DecodeTag(rawTag)
+		if num < protowire.MinValidNumber {
+			return nil, fmt.Errorf("invalid field number %d", num)
+		}
+		switch typ {
+		case protowire.VarintType:
+			val, err := binary.ReadUvarint(reader)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read varint field %d: %w", num, err)
+			}
+			rest = protowire.AppendTag(rest, num, typ)
+			rest = protowire.AppendVarint(rest, val)
+		case protowire.Fixed32Type, protowire.Fixed64Type:
+			size := 4
+			if typ == protowire.Fixed64Type {
+				size = 8
+			}
+			val := make([]byte, size)
+			_, err = io.ReadFull(reader, val)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read fixed field %d: %w", num, err)
+			}
+			rest = protowire.AppendTag(rest, num, typ)
+			rest = append(rest, val...)
+		case protowire.BytesType:
+			length, err := binary.ReadUvarint(reader)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read length of field %d: %w", num, err)
+			} else if length > MaxStreamedHistorySyncFieldSize {
+				return nil, fmt.Errorf("field %d is too large (%d bytes)", num, length)
+			}
+			val := make([]byte, length)
+			_, err = io.ReadFull(reader, val)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read field %d: %w", num, err)
+			}
+			if num == historySyncConversationsField {
+				var conv waProto.Conversation
+				err = proto.Unmarshal(val, &conv)
+				if err != nil {
+					return nil, fmt.Errorf("failed to unmarshal conversation: %w", err)
+				}
+				err = fn(&conv)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				rest = protowire.AppendTag(rest, num, typ)
+				rest = protowire.AppendBytes(rest, val)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d in field %d", typ, num)
+		}
+	}
+	var historySync waProto.HistorySync
+	err := proto.Unmarshal(rest, &historySync)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal non-conversation fields: %w", err)
+	}
+	return &historySync, nil
+}
+
+// streamHistorySync downloads and decodes the given history sync, dispatching each conversation as it's decoded.
+//
+// If decoding fails partway, job.streamedConversations is updated to the number of conversations that were
+// dispatched, and retries skip that many conversations, so each conversation is only dispatched once.
+func (cli *Client) streamHistorySync(job *historySyncJob) (*waProto.HistorySync, error) {
+	notif := job.notif
+	data, err := cli.Download(notif)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download history sync data: %w", err)
+	}
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zlib reader for history sync data: %w", err)
+	}
+	defer reader.Close()
+	syncType := waProto.HistorySync_HistorySyncType(notif.GetSyncType())
+	var index int
+	historySync, err := DecodeHistorySyncStream(reader, func(conv *waProto.Conversation) error {
+		index++
+		if index <= job.streamedConversations {
+			return nil
+		}
+		convs := []*waProto.Conversation{conv}
+		cli.storeHistoricalMessageSecrets(convs)
+		cli.storeHistoricalDisappearingTimers(convs)
+		cli.indexHistoricalMessages(convs)
+		cli.dispatchEvent(&events.HistorySyncConversation{
+			SyncType:     syncType,
+			ChunkOrder:   notif.GetChunkOrder(),
+			Conversation: conv,
+		})
+		job.streamedConversations = index
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode history sync data: %w", err)
+	}
+	return historySync, nil
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/fingerprint"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/sofyan48/whatsmeow/store"
+	"github.com/sofyan48/whatsmeow/types"
+)
+
+const (
+	fingerprintVersion    = 0
+	fingerprintIterations = 5200
+)
+
+func numericFingerprint(stableIdentifier string, identityKey [32]byte) []byte {
+	publicKey := append([]byte{ecc.DjbType}, identityKey[:]...)
+	hash := sha512.New()
+	_ = binary.Write(hash, binary.BigEndian, uint16(fingerprintVersion))
+	hash.Write(publicKey)
+	hash.Write([]byte(stableIdentifier))
+	output := hash.Sum(nil)
+	for i := 0; i < fingerprintIterations; i++ {
+		hash.Reset()
+		hash.Write(output)
+		hash.Write(publicKey)
+		output = hash.Sum(output[:0])
+	}
+	return output
+}
+
+func scannableFingerprint(localFingerprint, remoteFingerprint []byte) []byte {
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, fingerprintVersion)
+	data = protowire.AppendTag(data, 2, protowire.BytesType)
+	data = protowire.AppendBytes(data, protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), localFingerprint))
+	data = protowire.AppendTag(data, 3, protowire.BytesType)
+	data = protowire.AppendBytes(data, protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), remoteFingerprint))
+	return data
+}
+
+// getIdentityKey gets the identity key of the given user's primary device. If it's not stored,
+// it's fetched from the server using a prekey bundle request (without creating a session).
+func (cli *Client) getIdentityKey(ctx context.Context, user types.JID) ([32]byte, error) {
+	primary := types.NewJID(user.User, user.Server)
+	getter, ok := cli.Store.Identities.(store.IdentityGetter)
+	if !ok {
+		return cli.fetchIdentityKey(ctx, primary)
+	}
+	key, found, err := getter.GetIdentity(primary.SignalAddress().String())
+	if err != nil {
+		return key, fmt.Errorf("failed to get stored identity: %w", err)
+	} else if found {
+		return key, nil
+	}
+	return cli.fetchIdentityKey(ctx, primary)
+}
+
+// fetchIdentityKey fetches the current identity key of the given user's primary device from the server
+// using a prekey bundle request (without creating a session).
+func (cli *Client) fetchIdentityKey(ctx context.Context, user types.JID) (key [32]byte, err error) {
+	primary := types.NewJID(user.User, user.Server)
+	bundles, err := cli.fetchPreKeys(ctx, []types.JID{primary})
+	if err != nil {
+		return key, err
+	}
+	resp, ok := bundles[primary]
+	if !ok {
+		return key, fmt.Errorf("server didn't return identity of %s", primary)
+	} else if resp.err != nil {
+		return key, fmt.Errorf("failed to parse identity of %s: %w", primary, resp.err)
+	}
+	return resp.bundle.IdentityKey().PublicKey().PublicKey(), nil
+}
+
+func (cli *Client) getFingerprints(ctx context.Context, user types.JID) (localFingerprint, remoteFingerprint []byte, theirKey [32]byte, err error) {
+	ownID := cli.getOwnID()
+	if ownID.IsEmpty() || cli.Store.Account == nil {
+		err = ErrNotLoggedIn
+		return
+	}
+	ownKey := cli.Store.Account.GetAccountSignatureKey()
+	if len(ownKey) != 32 {
+		err = fmt.Errorf("unexpected length %d of own account signature key", len(ownKey))
+		return
+	}
+	theirKey, err = cli.getIdentityKey(ctx, user)
+	if err != nil {
+		return
+	}
+	localFingerprint = numericFingerprint(ownID.User, *(*[32]byte)(ownKey))
+	remoteFingerprint = numericFingerprint(user.User, theirKey)
+	return
+}
+
+// GetSafetyNumber computes the safety number (security code) for the conversation with the given user.
+//
+// The safety number is derived from the identity keys of the primary devices of both accounts, so it
+// changes when either user reinstalls WhatsApp or changes their phone.
+func (cli *Client) GetSafetyNumber(ctx context.Context, user types.JID) (*types.SafetyNumber, error) {
+	localFingerprint, remoteFingerprint, _, err := cli.getFingerprints(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SafetyNumber{
+		JID:       user.ToNonAD(),
+		Digits:    fingerprint.NewDisplay(localFingerprint[:30], remoteFingerprint[:30]).DisplayText(),
+		Scannable: scannableFingerprint(localFingerprint[:32], remoteFingerprint[:32]),
+	}, nil
+}
+
+// VerifyScannedSafetyNumber checks if the given data scanned from the other user's safety number QR code
+// matches the conversation. If it does, the user's current identity is marked as trusted.
+func (cli *Client) VerifyScannedSafetyNumber(ctx context.Context, user types.JID, scanned []byte) (bool, error) {
+	localFingerprint, remoteFingerprint, theirKey, err := cli.getFingerprints(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	// The other user's QR code has the fingerprints the other way around
+	expected := scannableFingerprint(remoteFingerprint[:32], localFingerprint[:32])
+	if subtle.ConstantTimeCompare(expected, scanned) != 1 {
+		return false, nil
+	}
+	return true, cli.markIdentityTrusted(user, theirKey)
+}
+
+// MarkIdentityTrusted fetches the current identity of the given user from the server and marks it as verified.
+//
+// If the identity changes later, GetIdentityStatus will report it as changed, and sending messages to the user
+// will fail with ErrIdentityChanged if BlockSendToChangedIdentity is enabled, until this is called again.
+func (cli *Client) MarkIdentityTrusted(ctx context.Context, user types.JID) error {
+	key, err := cli.fetchIdentityKey(ctx, user)
+	if err != nil {
+		return err
+	}
+	return cli.markIdentityTrusted(user, key)
+}
+
+// markIdentityTrusted stores the given key as the verified identity of the user. The key is also stored as the
+// user's signal identity, so that sessions can be established with it. If a different identity was stored,
+// the old session is deleted, as it was established with the old identity.
+func (cli *Client) markIdentityTrusted(user types.JID, key [32]byte) error {
+	if cli.Store.Verifications == nil {
+		return errors.New("identity verification store is not available")
+	}
+	getter, ok := cli.Store.Identities.(store.IdentityGetter)
+	if !ok {
+		return errors.New("identity store doesn't support getting stored identities")
+	}
+	address := types.NewJID(user.User, user.Server).SignalAddress().String()
+	storedKey, found, err := getter.GetIdentity(address)
+	if err != nil {
+		return fmt.Errorf("failed to get stored identity: %w", err)
+	} else if found && storedKey != key {
+		err = cli.Store.Sessions.DeleteSession(address)
+		if err != nil {
+			return fmt.Errorf("failed to delete session with old identity: %w", err)
+		}
+	}
+	if !found || storedKey != key {
+		err = cli.Store.Identities.PutIdentity(address, key)
+		if err != nil {
+			return fmt.Errorf("failed to store identity: %w", err)
+		}
+	}
+	return cli.Store.Verifications.PutVerifiedIdentity(store.VerifiedIdentity{
+		JID:         user.ToNonAD(),
+		IdentityKey: key,
+		VerifiedAt:  time.Now(),
+	})
+}
+
+// UnverifyIdentity removes the verified state of the given user.
+func (cli *Client) UnverifyIdentity(user types.JID) error {
+	if cli.Store.Verifications == nil {
+		return errors.New("identity verification store is not available")
+	}
+	return cli.Store.Verifications.DeleteVerifiedIdentity(user)
+}
+
+// GetIdentityStatus returns the current identity key of the given user and whether it has been verified.
+func (cli *Client) GetIdentityStatus(ctx context.Context, user types.JID) (*types.IdentityStatus, error) {
+	key, err := cli.getIdentityKey(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	status := &types.IdentityStatus{
+		JID:         user.ToNonAD(),
+		IdentityKey: key,
+	}
+	if cli.Store.Verifications != nil {
+		verified, err := cli.Store.Verifications.GetVerifiedIdentity(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get verified identity: %w", err)
+		} else if verified != nil {
+			status.Verified = true
+			status.VerifiedAt = verified.VerifiedAt
+			status.Changed = verified.IdentityKey != key
+		}
+	}
+	return status, nil
+}
+
+func (cli *Client) isIdentityVerified(user types.JID) bool {
+	if cli.Store.Verifications == nil {
+		return false
+	}
+	verified, err := cli.Store.Verifications.GetVerifiedIdentity(user)
+	if err != nil {
+		cli.Log.Warnf("Failed to check if identity of %s is verified: %v", user, err)
+		return false
+	}
+	return verified != nil
+}
+
+// checkVerifiedIdentity returns ErrIdentityChanged if the identity of the given user was verified,
+// but the current identity is different. If no identity is stored (e.g. because it was deleted due to
+// an identity change), the current identity is fetched from the server.
+func (cli *Client) checkVerifiedIdentity(ctx context.Context, user types.JID) error {
+	if cli.Store.Verifications == nil {
+		return nil
+	}
+	verified, err := cli.Store.Verifications.GetVerifiedIdentity(user)
+	if err != nil {
+		return fmt.Errorf("failed to get verified identity: %w", err)
+	} else if verified == nil {
+		return nil
+	}
+	key, err := cli.getIdentityKey(ctx, user)
+	if err != nil {
+		return err
+	} else if key != verified.IdentityKey {
+		return ErrIdentityChanged
+	}
+	return nil
+}
+
+// shouldBlockIdentityChange returns true if an untrusted identity error for the given device
+// shouldn't be handled automatically because the user's identity was verified.
+func (cli *Client) shouldBlockIdentityChange(device types.JID) bool {
+	return cli.BlockSendToChangedIdentity && device.Device == 0 && cli.isIdentityVerified(device)
+}
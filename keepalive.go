@@ -9,6 +9,7 @@ package whatsmeow
 import (
 	"context"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/sofyan48/whatsmeow/types"
@@ -25,28 +26,173 @@ var (
 
 	// KeepAliveMaxFailTime specifies the maximum time to wait before forcing a reconnect if keepalives fail repeatedly.
 	KeepAliveMaxFailTime = 3 * time.Minute
+	// KeepAliveFrameTimeout specifies the maximum time without receiving any frames from the server before the
+	// connection is considered half-open and a reconnect is forced. Zero disables the check.
+	KeepAliveFrameTimeout = 90 * time.Second
 )
 
+// KeepAliveConfig contains per-client keepalive settings. Zero fields fall back to the package-level KeepAlive* variables.
+type KeepAliveConfig struct {
+	IntervalMin      time.Duration
+	IntervalMax      time.Duration
+	ResponseDeadline time.Duration
+	MaxFailTime      time.Duration
+	FrameTimeout     time.Duration
+}
+
+func (cli *Client) getKeepAliveConfig() KeepAliveConfig {
+	conf := KeepAliveConfig{
+		IntervalMin:      KeepAliveIntervalMin,
+		IntervalMax:      KeepAliveIntervalMax,
+		ResponseDeadline: KeepAliveResponseDeadline,
+		MaxFailTime:      KeepAliveMaxFailTime,
+		FrameTimeout:     KeepAliveFrameTimeout,
+	}
+	if cli.KeepAlive != nil {
+		if cli.KeepAlive.IntervalMin > 0 {
+			conf.IntervalMin = cli.KeepAlive.IntervalMin
+		}
+		if cli.KeepAlive.IntervalMax > 0 {
+			conf.IntervalMax = cli.KeepAlive.IntervalMax
+		}
+		if cli.KeepAlive.ResponseDeadline > 0 {
+			conf.ResponseDeadline = cli.KeepAlive.ResponseDeadline
+		}
+		if cli.KeepAlive.MaxFailTime > 0 {
+			conf.MaxFailTime = cli.KeepAlive.MaxFailTime
+		}
+		if cli.KeepAlive.FrameTimeout > 0 {
+			conf.FrameTimeout = cli.KeepAlive.FrameTimeout
+		}
+	}
+	if conf.IntervalMax <= conf.IntervalMin {
+		conf.IntervalMax = conf.IntervalMin + time.Millisecond
+	}
+	return conf
+}
+
+type connectionHealthTracker struct {
+	lock   sync.Mutex
+	health types.ConnectionHealth
+}
+
+// rttSmoothingFactor is the weight of new samples in the smoothed RTT (same as TCP's SRTT).
+const rttSmoothingFactor = 0.125
+
+func (cli *Client) setConnectionHealth(state types.ConnectionHealthState, update func(health *types.ConnectionHealth)) {
+	cli.connHealth.lock.Lock()
+	prevState := cli.connHealth.health.State
+	if update != nil {
+		update(&cli.connHealth.health)
+	}
+	cli.connHealth.health.State = state
+	health := cli.connHealth.health
+	cli.connHealth.lock.Unlock()
+	if prevState != state {
+		go cli.dispatchEvent(&events.ConnectionHealthChanged{Previous: prevState, Health: health})
+	}
+}
+
+func (cli *Client) resetConnectionHealth() {
+	cli.setConnectionHealth(types.ConnectionHealthHealthy, func(health *types.ConnectionHealth) {
+		*health = types.ConnectionHealth{LastFrameReceived: time.Now()}
+	})
+}
+
+func (cli *Client) markFrameReceived() {
+	cli.lastFrameReceived.Store(time.Now().UnixNano())
+}
+
+func (cli *Client) recordKeepAliveResult(rtt time.Duration, success bool) {
+	state := types.ConnectionHealthHealthy
+	if !success {
+		state = types.ConnectionHealthDegraded
+	}
+	cli.setConnectionHealth(state, func(health *types.ConnectionHealth) {
+		health.PingsSent++
+		if !success {
+			health.PingsFailed++
+			health.ConsecutiveFailures++
+			return
+		}
+		health.ConsecutiveFailures = 0
+		health.LastPingSuccess = time.Now()
+		health.LastRTT = rtt
+		if health.SmoothedRTT == 0 {
+			health.SmoothedRTT = rtt
+		} else {
+			health.SmoothedRTT += time.Duration(rttSmoothingFactor * float64(rtt-health.SmoothedRTT))
+		}
+		if health.MinRTT == 0 || rtt < health.MinRTT {
+			health.MinRTT = rtt
+		}
+		if rtt > health.MaxRTT {
+			health.MaxRTT = rtt
+		}
+	})
+}
+
+// GetConnectionHealth returns the health state and round-trip time statistics of the current connection.
+// The statistics are reset whenever a new connection is made.
+func (cli *Client) GetConnectionHealth() types.ConnectionHealth {
+	cli.connHealth.lock.Lock()
+	health := cli.connHealth.health
+	cli.connHealth.lock.Unlock()
+	if !cli.IsConnected() {
+		health.State = types.ConnectionHealthDisconnected
+	}
+	if lastFrame := cli.lastFrameReceived.Load(); lastFrame > 0 {
+		health.LastFrameReceived = time.Unix(0, lastFrame)
+	}
+	return health
+}
+
+func (cli *Client) forceReconnect(reason string) {
+	cli.Log.Debugf("Forcing reconnect due to %s", reason)
+	cli.setConnectionHealth(types.ConnectionHealthDead, nil)
+	cli.Disconnect()
+	go cli.autoReconnect()
+}
+
 func (cli *Client) keepAliveLoop(ctx context.Context) {
 	lastSuccess := time.Now()
 	var errorCount int
+	cli.markFrameReceived()
+	cli.resetConnectionHealth()
 	for {
-		interval := rand.Int63n(KeepAliveIntervalMax.Milliseconds()-KeepAliveIntervalMin.Milliseconds()) + KeepAliveIntervalMin.Milliseconds()
+		conf := cli.getKeepAliveConfig()
+		interval := conf.IntervalMin
+		if intervalRange := conf.IntervalMax.Milliseconds() - conf.IntervalMin.Milliseconds(); intervalRange > 0 {
+			interval = time.Duration(rand.Int63n(intervalRange)+conf.IntervalMin.Milliseconds()) * time.Millisecond
+		}
+		if errorCount > 0 && conf.ResponseDeadline < interval {
+			// Check again sooner if the previous ping failed to detect dead connections faster
+			interval = conf.ResponseDeadline
+		}
 		select {
-		case <-time.After(time.Duration(interval) * time.Millisecond):
-			isSuccess, shouldContinue := cli.sendKeepAlive(ctx)
+		case <-time.After(interval):
+			if conf.FrameTimeout > 0 && cli.EnableAutoReconnect {
+				lastFrame := time.Unix(0, cli.lastFrameReceived.Load())
+				if time.Since(lastFrame) > conf.FrameTimeout {
+					cli.forceReconnect("no frames received since " + lastFrame.String())
+					return
+				}
+			}
+			start := time.Now()
+			isSuccess, shouldContinue := cli.sendKeepAlive(ctx, conf.ResponseDeadline)
 			if !shouldContinue {
 				return
-			} else if !isSuccess {
+			}
+			cli.recordKeepAliveResult(time.Since(start), isSuccess)
+			if !isSuccess {
 				errorCount++
 				go cli.dispatchEvent(&events.KeepAliveTimeout{
 					ErrorCount:  errorCount,
 					LastSuccess: lastSuccess,
 				})
-				if cli.EnableAutoReconnect && time.Since(lastSuccess) > KeepAliveMaxFailTime {
-					cli.Log.Debugf("Forcing reconnect due to keepalive failure")
-					cli.Disconnect()
-					go cli.autoReconnect()
+				if cli.EnableAutoReconnect && time.Since(lastSuccess) > conf.MaxFailTime {
+					cli.forceReconnect("keepalive failure")
+					return
 				}
 			} else {
 				if errorCount > 0 {
@@ -61,7 +207,7 @@ func (cli *Client) keepAliveLoop(ctx context.Context) {
 	}
 }
 
-func (cli *Client) sendKeepAlive(ctx context.Context) (isSuccess, shouldContinue bool) {
+func (cli *Client) sendKeepAlive(ctx context.Context, deadline time.Duration) (isSuccess, shouldContinue bool) {
 	respCh, err := cli.sendIQAsync(infoQuery{
 		Namespace: "w:p",
 		Type:      "get",
@@ -75,7 +221,7 @@ func (cli *Client) sendKeepAlive(ctx context.Context) (isSuccess, shouldContinue
 	case <-respCh:
 		// All good
 		return true, true
-	case <-time.After(KeepAliveResponseDeadline):
+	case <-time.After(deadline):
 		cli.Log.Warnf("Keepalive timed out")
 		return false, true
 	case <-ctx.Done():
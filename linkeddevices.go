@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+
+	waBinary "github.com/sofyan48/whatsmeow/binary"
+	"github.com/sofyan48/whatsmeow/store"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+// GetLinkedDevices fetches the list of devices linked to the user's own account, including the primary device.
+//
+// The device list is always fetched from the server, and the device list cache is updated with the result.
+// Last activity times aren't available, and the platform is only known for the primary and current devices
+// (see types.LinkedDevice).
+func (cli *Client) GetLinkedDevices() ([]types.LinkedDevice, error) {
+	return cli.GetLinkedDevicesContext(context.Background())
+}
+
+func (cli *Client) GetLinkedDevicesContext(ctx context.Context) ([]types.LinkedDevice, error) {
+	ownID := cli.getOwnID()
+	if ownID.IsEmpty() {
+		return nil, ErrNotLoggedIn
+	}
+	list, err := cli.usync(ctx, []types.JID{ownID.ToNonAD()}, "query", "message", []waBinary.Node{
+		{Tag: "devices", Attrs: waBinary.Attrs{"version": "2"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	user, ok := list.GetOptionalChildByTag("user")
+	if !ok {
+		return nil, &ElementMissingError{Tag: "user", In: "response to own device list query"}
+	}
+	deviceList, ok := user.GetOptionalChildByTag("devices", "device-list")
+	if !ok {
+		return nil, &ElementMissingError{Tag: "device-list", In: "response to own device list query"}
+	}
+	var devices []types.LinkedDevice
+	var jids []types.JID
+	for _, child := range deviceList.GetChildren() {
+		if child.Tag != "device" {
+			continue
+		}
+		ag := child.AttrGetter()
+		deviceID := ag.Int("id")
+		keyIndex := ag.OptionalInt("key-index")
+		if !ag.OK() {
+			return nil, fmt.Errorf("failed to parse device in own device list: %w", ag.Error())
+		}
+		jid := types.NewADJID(ownID.User, 0, byte(deviceID))
+		device := types.LinkedDevice{
+			JID:       jid,
+			KeyIndex:  keyIndex,
+			IsPrimary: deviceID == 0,
+			IsCurrent: jid.Device == ownID.Device,
+		}
+		if device.IsPrimary {
+			device.Platform = cli.Store.Platform
+		} else if device.IsCurrent {
+			device.Platform = store.DeviceProps.GetOs()
+		}
+		devices = append(devices, device)
+		jids = append(jids, jid)
+	}
+	cli.userDevicesCacheLock.Lock()
+	cli.userDevicesCache[ownID.ToNonAD()] = deviceCache{devices: jids, dhash: participantListHashV2(jids)}
+	cli.userDevicesCacheLock.Unlock()
+	return devices, nil
+}
+
+// RemoveLinkedDevice unlinks the given companion device from the user's own account.
+//
+// If the JID is the device the client is running as, this is equivalent to Logout. The primary device can't be removed.
+// Note that the server may reject unlinking other companions from a companion device, in which case an error is returned.
+func (cli *Client) RemoveLinkedDevice(jid types.JID) error {
+	ownID := cli.getOwnID()
+	if ownID.IsEmpty() {
+		return ErrNotLoggedIn
+	} else if jid.User != ownID.User || jid.Server != ownID.Server {
+		return ErrNotOwnDevice
+	} else if jid.Device == 0 {
+		return ErrCannotRemovePrimaryDevice
+	} else if jid.Device == ownID.Device {
+		return cli.Logout()
+	}
+	_, err := cli.sendIQ(infoQuery{
+		Namespace: "md",
+		Type:      iqSet,
+		To:        types.ServerJID,
+		Content: []waBinary.Node{{
+			Tag: "remove-companion-device",
+			Attrs: waBinary.Attrs{
+				"jid":    jid,
+				"reason": "user_initiated",
+			},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("error sending unlink request: %w", err)
+	}
+	return nil
+}
+
+func diffDeviceLists(oldList, newList []types.JID) (added, removed []types.JID) {
+	oldSet := make(map[types.JID]struct{}, len(oldList))
+	for _, jid := range oldList {
+		oldSet[jid] = struct{}{}
+	}
+	newSet := make(map[types.JID]struct{}, len(newList))
+	for _, jid := range newList {
+		newSet[jid] = struct{}{}
+		if _, ok := oldSet[jid]; !ok {
+			added = append(added, jid)
+		}
+	}
+	for _, jid := range oldList {
+		if _, ok := newSet[jid]; !ok {
+			removed = append(removed, jid)
+		}
+	}
+	return
+}
+
+// dispatchOwnDevicesChanged dispatches an OwnDevicesChanged event if the new list differs from the old one.
+// It must only be called after the hash of the new list has been verified and with the userDevicesCache lock held,
+// so that the same change arriving in multiple notifications is only dispatched once.
+func (cli *Client) dispatchOwnDevicesChanged(oldList []types.JID, oldListKnown bool, newList []types.JID) {
+	evt := &events.OwnDevicesChanged{Devices: newList}
+	if oldListKnown {
+		evt.Added, evt.Removed = diffDeviceLists(oldList, newList)
+		if len(evt.Added) == 0 && len(evt.Removed) == 0 {
+			return
+		}
+	}
+	go cli.dispatchEvent(evt)
+}
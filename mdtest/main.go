@@ -928,6 +928,34 @@ func handleCmd(cmd string, args []string) {
 		if err != nil {
 			log.Errorf("Error changing chat's pin state: %v", err)
 		}
+	case "signalmaintenance":
+		opts := whatsmeow.SignalMaintenanceOptions{
+			PruneDeletedDevices: true,
+			PruneLeftGroups:     true,
+		}
+		for _, arg := range args {
+			if arg == "--dry-run" {
+				opts.DryRun = true
+			} else if days, err := strconv.Atoi(arg); err == nil {
+				opts.MaxIdleAge = time.Duration(days) * 24 * time.Hour
+			} else {
+				log.Errorf("Usage: signalmaintenance [--dry-run] [max idle days]")
+				return
+			}
+		}
+		report, err := cli.RunSignalMaintenance(context.Background(), opts)
+		if err != nil {
+			log.Errorf("Failed to run signal maintenance: %v", err)
+			return
+		}
+		verb := "Removed"
+		if report.DryRun {
+			verb = "Would remove"
+		}
+		log.Infof("%s %d sessions of deleted devices: %v", verb, len(report.DeletedDeviceSessions), report.DeletedDeviceSessions)
+		log.Infof("%s sender keys of %d left groups: %v", verb, len(report.LeftGroupSenderKeys), report.LeftGroupSenderKeys)
+		log.Infof("%s %d stale sessions: %v", verb, len(report.StaleSessions), report.StaleSessions)
+		log.Infof("%s %d stale identities: %v", verb, len(report.StaleIdentities), report.StaleIdentities)
 	case "getblocklist":
 		blocklist, err := cli.GetBlocklist()
 		if err != nil {
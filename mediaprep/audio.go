@@ -0,0 +1,204 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package mediaprep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Opus always uses a 48 kHz clock for granule positions regardless of the input sample rate.
+const opusGranuleRate = 48000
+
+type oggPage struct {
+	granule  int64
+	packets  [][]byte
+	bodySize int
+}
+
+// parseOggPages splits an Ogg bitstream into pages. Packets spanning multiple pages are joined into the page they end in.
+func parseOggPages(data []byte) ([]oggPage, error) {
+	var pages []oggPage
+	var partial []byte
+	for len(data) > 0 {
+		if len(data) < 27 || !bytes.Equal(data[:4], []byte("OggS")) {
+			return pages, fmt.Errorf("invalid ogg page header")
+		}
+		segmentCount := int(data[26])
+		if len(data) < 27+segmentCount {
+			return pages, fmt.Errorf("truncated ogg segment table")
+		}
+		page := oggPage{granule: int64(binary.LittleEndian.Uint64(data[6:14]))}
+		segments := data[27 : 27+segmentCount]
+		body := data[27+segmentCount:]
+		offset := 0
+		for _, segmentLength := range segments {
+			end := offset + int(segmentLength)
+			if end > len(body) {
+				return pages, fmt.Errorf("truncated ogg page body")
+			}
+			partial = append(partial, body[offset:end]...)
+			offset = end
+			if segmentLength < 255 {
+				page.packets = append(page.packets, partial)
+				partial = nil
+			}
+		}
+		page.bodySize = offset
+		pages = append(pages, page)
+		data = body[offset:]
+	}
+	return pages, nil
+}
+
+func isOggOpus(data []byte) bool {
+	if len(data) < 28 || !bytes.Equal(data[:4], []byte("OggS")) {
+		return false
+	}
+	bodyStart := 27 + int(data[26])
+	return len(data) >= bodyStart+8 && bytes.Equal(data[bodyStart:bodyStart+8], []byte("OpusHead"))
+}
+
+// analyzeOggOpus reads the duration of an Ogg Opus file from the granule positions.
+//
+// Decoding Opus isn't possible in pure Go without a large dependency, so the waveform is approximated
+// from the amount of compressed data per time slice: louder audio needs more bits with Opus' VBR mode.
+func analyzeOggOpus(data []byte) (time.Duration, []byte, error) {
+	pages, err := parseOggPages(data)
+	if len(pages) < 2 || len(pages[0].packets) == 0 {
+		if err == nil {
+			err = ErrNotAudio
+		}
+		return 0, nil, err
+	}
+	head := pages[0].packets[0]
+	if len(head) < 19 || !bytes.Equal(head[:8], []byte("OpusHead")) {
+		return 0, nil, ErrNotAudio
+	}
+	preSkip := int64(binary.LittleEndian.Uint16(head[10:12]))
+	var lastGranule int64
+	for _, page := range pages {
+		if page.granule > lastGranule {
+			lastGranule = page.granule
+		}
+	}
+	samples := lastGranule - preSkip
+	if samples <= 0 {
+		return 0, nil, fmt.Errorf("no audio samples in ogg file")
+	}
+	duration := time.Duration(samples) * time.Second / opusGranuleRate
+
+	buckets := make([]float64, WaveformLength)
+	var prevGranule int64
+	// The first two pages contain the OpusHead and OpusTags headers
+	for _, page := range pages[2:] {
+		if page.granule <= prevGranule {
+			continue
+		}
+		start := int(prevGranule * WaveformLength / lastGranule)
+		end := int(page.granule * WaveformLength / lastGranule)
+		if end <= start {
+			end = start + 1
+		}
+		if end > WaveformLength {
+			end = WaveformLength
+			if start >= end {
+				start = end - 1
+			}
+		}
+		for i := start; i < end; i++ {
+			buckets[i] += float64(page.bodySize) / float64(end-start)
+		}
+		prevGranule = page.granule
+	}
+	return duration, normalizeWaveform(buckets), err
+}
+
+// analyzeWAV reads the duration and waveform of a PCM WAV file.
+func analyzeWAV(data []byte) (time.Duration, []byte, error) {
+	if len(data) < 12 || !bytes.Equal(data[:4], []byte("RIFF")) || !bytes.Equal(data[8:12], []byte("WAVE")) {
+		return 0, nil, ErrNotAudio
+	}
+	var format, channels, bitsPerSample uint16
+	var sampleRate uint32
+	var pcm []byte
+	for chunks := data[12:]; len(chunks) >= 8; {
+		id := string(chunks[:4])
+		size := int(binary.LittleEndian.Uint32(chunks[4:8]))
+		chunks = chunks[8:]
+		if size > len(chunks) {
+			size = len(chunks)
+		}
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return 0, nil, fmt.Errorf("invalid wav format chunk")
+			}
+			format = binary.LittleEndian.Uint16(chunks[0:2])
+			channels = binary.LittleEndian.Uint16(chunks[2:4])
+			sampleRate = binary.LittleEndian.Uint32(chunks[4:8])
+			bitsPerSample = binary.LittleEndian.Uint16(chunks[14:16])
+		case "data":
+			pcm = chunks[:size]
+		}
+		// Chunks are padded to an even number of bytes
+		if size%2 == 1 && size < len(chunks) {
+			size++
+		}
+		chunks = chunks[size:]
+	}
+	if format != 1 || channels == 0 || sampleRate == 0 || (bitsPerSample != 8 && bitsPerSample != 16) {
+		return 0, nil, fmt.Errorf("%w: only 8 and 16 bit PCM wav files are supported", ErrNotAudio)
+	}
+	frameSize := int(channels) * int(bitsPerSample/8)
+	frames := len(pcm) / frameSize
+	if frames == 0 {
+		return 0, nil, fmt.Errorf("no audio samples in wav file")
+	}
+	duration := time.Duration(frames) * time.Second / time.Duration(sampleRate)
+
+	buckets := make([]float64, WaveformLength)
+	counts := make([]int, WaveformLength)
+	for i := 0; i < frames; i++ {
+		frame := pcm[i*frameSize : (i+1)*frameSize]
+		var amplitude float64
+		// Only the first channel is used for the waveform
+		if bitsPerSample == 8 {
+			amplitude = math.Abs(float64(int(frame[0])-128)) / 128
+		} else {
+			amplitude = math.Abs(float64(int16(binary.LittleEndian.Uint16(frame)))) / 32768
+		}
+		bucket := i * WaveformLength / frames
+		buckets[bucket] += amplitude * amplitude
+		counts[bucket]++
+	}
+	for i := range buckets {
+		if counts[i] > 0 {
+			buckets[i] = math.Sqrt(buckets[i] / float64(counts[i]))
+		}
+	}
+	return duration, normalizeWaveform(buckets), nil
+}
+
+// normalizeWaveform scales the values so that the loudest one is 100, which is the format WhatsApp clients use.
+func normalizeWaveform(values []float64) []byte {
+	var peak float64
+	for _, val := range values {
+		peak = math.Max(peak, val)
+	}
+	waveform := make([]byte, len(values))
+	if peak == 0 {
+		return waveform
+	}
+	for i, val := range values {
+		waveform[i] = byte(math.Round(val / peak * 100))
+	}
+	return waveform
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package mediaprep extracts the metadata WhatsApp clients expect in media messages
+// (MIME type, dimensions, thumbnails, durations and waveforms) and builds ready-to-send messages from local files.
+//
+// Everything is implemented in pure Go, so only the image formats supported by the standard library
+// (JPEG, PNG and GIF) get dimensions and thumbnails, and only Ogg Opus and PCM WAV audio files get durations and waveforms.
+package mediaprep
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sofyan48/whatsmeow"
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/msgbuilder"
+)
+
+// ThumbnailSize is the maximum width and height of generated JPEG thumbnails.
+var ThumbnailSize = 72
+
+// ThumbnailQuality is the JPEG quality used for generated thumbnails.
+var ThumbnailQuality = 60
+
+// WaveformLength is the number of samples in generated voice message waveforms.
+const WaveformLength = 64
+
+var (
+	// ErrNotImage is returned by PrepareImage and GenerateThumbnail if the file can't be decoded as an image.
+	ErrNotImage = errors.New("file is not a supported image")
+	// ErrNotAudio is returned by PrepareAudio if the file doesn't look like audio.
+	ErrNotAudio = errors.New("file is not a supported audio file")
+)
+
+// Metadata contains the information extracted from a media file.
+type Metadata struct {
+	MimeType   string
+	FileLength uint64
+
+	// Only present for images that Go can decode
+	Width     uint32
+	Height    uint32
+	Thumbnail []byte
+
+	// Only present for Ogg Opus and PCM WAV audio
+	Duration time.Duration
+	Waveform []byte
+}
+
+// Analyze extracts metadata from the given file contents.
+//
+// Fields that can't be extracted for the detected file type are left empty. The file name is optional
+// and only used as a fallback for detecting the MIME type.
+func Analyze(data []byte, fileName string) *Metadata {
+	meta := &Metadata{
+		MimeType:   detectMimeType(data, fileName),
+		FileLength: uint64(len(data)),
+	}
+	switch {
+	case strings.HasPrefix(meta.MimeType, "image/"):
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err == nil {
+			meta.Width, meta.Height = uint32(cfg.Width), uint32(cfg.Height)
+			meta.Thumbnail, _ = GenerateThumbnail(data)
+		}
+	case strings.HasPrefix(meta.MimeType, "audio/ogg"):
+		meta.Duration, meta.Waveform, _ = analyzeOggOpus(data)
+	case meta.MimeType == "audio/wav":
+		meta.Duration, meta.Waveform, _ = analyzeWAV(data)
+	}
+	return meta
+}
+
+func detectMimeType(data []byte, fileName string) string {
+	mimeType := http.DetectContentType(data)
+	switch {
+	case mimeType == "application/ogg" || mimeType == "audio/ogg":
+		if isOggOpus(data) {
+			return "audio/ogg; codecs=opus"
+		}
+		return "audio/ogg"
+	case mimeType == "audio/wave":
+		return "audio/wav"
+	case mimeType == "application/octet-stream" || strings.HasPrefix(mimeType, "text/plain"):
+		if byExt := mime.TypeByExtension(filepath.Ext(fileName)); byExt != "" {
+			return byExt
+		}
+	}
+	return mimeType
+}
+
+// GenerateThumbnail decodes the given image and creates a JPEG thumbnail that fits in ThumbnailSize×ThumbnailSize.
+func GenerateThumbnail(data []byte) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotImage, err)
+	}
+	var buf bytes.Buffer
+	err = jpeg.Encode(&buf, downscale(img, ThumbnailSize), &jpeg.Options{Quality: ThumbnailQuality})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// downscale resizes the image to fit in maxSize×maxSize by averaging the source pixels covered by each target pixel.
+func downscale(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dstW, dstH := srcW, srcH
+	if srcW > maxSize || srcH > maxSize {
+		if srcW >= srcH {
+			dstW, dstH = maxSize, atLeastOne(srcH*maxSize/srcW)
+		} else {
+			dstW, dstH = atLeastOne(srcW*maxSize/srcH), maxSize
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := y0 + atLeastOne((y+1)*srcH/dstH-y*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := x0 + atLeastOne((x+1)*srcW/dstW-x*srcW/dstW)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+func atLeastOne(val int) int {
+	if val < 1 {
+		return 1
+	}
+	return val
+}
+
+func readAndUpload(ctx context.Context, cli *whatsmeow.Client, path string, mediaType whatsmeow.MediaType) (*Metadata, whatsmeow.UploadResponse, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, whatsmeow.UploadResponse{}, err
+	}
+	meta := Analyze(data, filepath.Base(path))
+	if mediaType == whatsmeow.MediaImage && meta.Width == 0 {
+		return meta, whatsmeow.UploadResponse{}, ErrNotImage
+	} else if mediaType == whatsmeow.MediaAudio && !strings.HasPrefix(meta.MimeType, "audio/") {
+		return meta, whatsmeow.UploadResponse{}, ErrNotAudio
+	}
+	uploaded, err := cli.Upload(ctx, data, mediaType)
+	if err != nil {
+		return meta, uploaded, fmt.Errorf("failed to upload file: %w", err)
+	}
+	return meta, uploaded, nil
+}
+
+// PrepareImage reads and uploads the given image file and returns an ImageMessage with all metadata filled.
+func PrepareImage(ctx context.Context, cli *whatsmeow.Client, path string) (*waProto.ImageMessage, error) {
+	meta, uploaded, err := readAndUpload(ctx, cli, path, whatsmeow.MediaImage)
+	if err != nil {
+		return nil, err
+	}
+	return msgbuilder.Image(uploaded, meta.MimeType).
+		Dimensions(meta.Width, meta.Height).
+		Thumbnail(meta.Thumbnail).
+		Build().GetImageMessage(), nil
+}
+
+// PrepareDocument reads and uploads the given file and returns a DocumentMessage with all metadata filled.
+//
+// Images will get a thumbnail, other files are sent as-is.
+func PrepareDocument(ctx context.Context, cli *whatsmeow.Client, path string) (*waProto.DocumentMessage, error) {
+	meta, uploaded, err := readAndUpload(ctx, cli, path, whatsmeow.MediaDocument)
+	if err != nil {
+		return nil, err
+	}
+	doc := msgbuilder.Document(uploaded, meta.MimeType, filepath.Base(path)).
+		Thumbnail(meta.Thumbnail).
+		Build().GetDocumentMessage()
+	if len(meta.Thumbnail) > 0 {
+		thumbCfg, _, err := image.DecodeConfig(bytes.NewReader(meta.Thumbnail))
+		if err == nil {
+			doc.ThumbnailWidth = proto.Uint32(uint32(thumbCfg.Width))
+			doc.ThumbnailHeight = proto.Uint32(uint32(thumbCfg.Height))
+		}
+	}
+	return doc, nil
+}
+
+// PrepareAudio reads and uploads the given audio file and returns an AudioMessage with all metadata filled.
+//
+// If ptt is true, the message will be shown as a voice message. Voice messages should be Ogg Opus files,
+// and will include a waveform.
+func PrepareAudio(ctx context.Context, cli *whatsmeow.Client, path string, ptt bool) (*waProto.AudioMessage, error) {
+	meta, uploaded, err := readAndUpload(ctx, cli, path, whatsmeow.MediaAudio)
+	if err != nil {
+		return nil, err
+	}
+	audio := msgbuilder.Audio(uploaded, meta.MimeType, ptt).
+		Duration(meta.Duration).
+		Build().GetAudioMessage()
+	if ptt {
+		audio.Waveform = meta.Waveform
+	}
+	return audio, nil
+}
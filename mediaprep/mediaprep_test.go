@@ -0,0 +1,65 @@
+package mediaprep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func makeWAV(sampleRate uint32, samples []int16) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+len(samples)*2))
+	buf.WriteString("WAVEfmt ")
+	for _, field := range []any{uint32(16), uint16(1), uint16(1), sampleRate, sampleRate * 2, uint16(2), uint16(16)} {
+		_ = binary.Write(&buf, binary.LittleEndian, field)
+	}
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(samples)*2))
+	_ = binary.Write(&buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
+
+func TestAnalyzeWAV(t *testing.T) {
+	samples := make([]int16, 8000)
+	for i := range samples {
+		// Get louder over time, alternating sign
+		samples[i] = int16(i * 4)
+		if i%2 == 1 {
+			samples[i] = -samples[i]
+		}
+	}
+	meta := Analyze(makeWAV(8000, samples), "")
+	if meta.MimeType != "audio/wav" {
+		t.Errorf("unexpected mime type %q", meta.MimeType)
+	}
+	if meta.Duration != time.Second {
+		t.Errorf("unexpected duration %s", meta.Duration)
+	}
+	if len(meta.Waveform) != WaveformLength || meta.Waveform[0] >= meta.Waveform[WaveformLength-1] || meta.Waveform[WaveformLength-1] != 100 {
+		t.Errorf("unexpected waveform %v", meta.Waveform)
+	}
+}
+
+func TestAnalyzeImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		img.Set(x, x/2, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	meta := Analyze(buf.Bytes(), "test.png")
+	if meta.MimeType != "image/png" || meta.Width != 400 || meta.Height != 200 {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+	thumb, _, err := image.DecodeConfig(bytes.NewReader(meta.Thumbnail))
+	if err != nil {
+		t.Fatalf("failed to decode thumbnail: %v", err)
+	} else if thumb.Width != ThumbnailSize || thumb.Height != ThumbnailSize/2 {
+		t.Errorf("unexpected thumbnail size %dx%d", thumb.Width, thumb.Height)
+	}
+}
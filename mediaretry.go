@@ -177,5 +177,6 @@ func (cli *Client) handleMediaRetryNotification(node *waBinary.Node) {
 		cli.Log.Warnf("Failed to parse media retry notification: %v", err)
 		return
 	}
+	cli.handleHistorySyncMediaRetry(evt)
 	cli.dispatchEvent(evt)
 }
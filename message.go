@@ -250,7 +250,7 @@ func (cli *Client) clearUntrustedIdentity(target types.JID) {
 	if err != nil {
 		cli.Log.Warnf("Failed to delete session with %s (untrusted identity) from store: %v", target, err)
 	}
-	cli.dispatchEvent(&events.IdentityChange{JID: target, Timestamp: time.Now(), Implicit: true})
+	cli.dispatchEvent(&events.IdentityChange{JID: target, Timestamp: time.Now(), Implicit: true, WasVerified: target.Device == 0 && cli.isIdentityVerified(target)})
 }
 
 func (cli *Client) decryptDM(child *waBinary.Node, from types.JID, isPreKey bool) ([]byte, error) {
@@ -265,7 +265,9 @@ func (cli *Client) decryptDM(child *waBinary.Node, from types.JID, isPreKey bool
 			return nil, fmt.Errorf("failed to parse prekey message: %w", err)
 		}
 		plaintext, _, err = cipher.DecryptMessageReturnKey(preKeyMsg)
-		if cli.AutoTrustIdentity && errors.Is(err, signalerror.ErrUntrustedIdentity) {
+		if errors.Is(err, signalerror.ErrUntrustedIdentity) && cli.shouldBlockIdentityChange(from) {
+			return nil, fmt.Errorf("%w: %v", ErrIdentityChanged, err)
+		} else if cli.AutoTrustIdentity && errors.Is(err, signalerror.ErrUntrustedIdentity) {
 			cli.Log.Warnf("Got %v error while trying to decrypt prekey message from %s, clearing stored identity and retrying", err, from)
 			cli.clearUntrustedIdentity(from)
 			plaintext, _, err = cipher.DecryptMessageReturnKey(preKeyMsg)
@@ -364,32 +366,56 @@ func (cli *Client) handleHistorySyncNotificationLoop() {
 			go cli.handleHistorySyncNotificationLoop()
 		}
 	}()
-	for notif := range cli.historySyncNotifications {
-		cli.handleHistorySyncNotification(notif)
+	for job := range cli.historySyncNotifications {
+		cli.handleHistorySyncNotification(job)
 	}
 }
 
-func (cli *Client) handleHistorySyncNotification(notif *waProto.HistorySyncNotification) {
+func (cli *Client) downloadHistorySync(notif *waProto.HistorySyncNotification) (*waProto.HistorySync, error) {
 	var historySync waProto.HistorySync
 	if data, err := cli.Download(notif); err != nil {
-		cli.Log.Errorf("Failed to download history sync data: %v", err)
+		return nil, fmt.Errorf("failed to download history sync data: %w", err)
 	} else if reader, err := zlib.NewReader(bytes.NewReader(data)); err != nil {
-		cli.Log.Errorf("Failed to create zlib reader for history sync data: %v", err)
+		return nil, fmt.Errorf("failed to create zlib reader for history sync data: %w", err)
 	} else if rawData, err := io.ReadAll(reader); err != nil {
-		cli.Log.Errorf("Failed to decompress history sync data: %v", err)
+		return nil, fmt.Errorf("failed to decompress history sync data: %w", err)
 	} else if err = proto.Unmarshal(rawData, &historySync); err != nil {
-		cli.Log.Errorf("Failed to unmarshal history sync data: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal history sync data: %w", err)
+	}
+	return &historySync, nil
+}
+
+func (cli *Client) handleHistorySyncNotification(job historySyncJob) {
+	if job.isRetry {
+		defer cli.finishHistorySyncRetry(job.info.ID)
+	}
+	var historySync *waProto.HistorySync
+	var err error
+	if cli.StreamHistorySync && job.notif.GetSyncType() != waProto.HistorySyncNotification_ON_DEMAND {
+		historySync, err = cli.streamHistorySync(&job)
 	} else {
-		cli.Log.Debugf("Received history sync (type %s, chunk %d)", historySync.GetSyncType(), historySync.GetChunkOrder())
-		if historySync.GetSyncType() == waProto.HistorySync_PUSH_NAME {
-			go cli.handleHistoricalPushNames(historySync.GetPushnames())
-		} else if len(historySync.GetConversations()) > 0 {
-			go cli.storeHistoricalMessageSecrets(historySync.GetConversations())
-		}
-		cli.dispatchEvent(&events.HistorySync{
-			Data: &historySync,
-		})
+		historySync, err = cli.downloadHistorySync(job.notif)
 	}
+	if err != nil {
+		cli.handleHistorySyncFailure(job, err)
+		return
+	} else if job.isRetry {
+		cli.clearHistorySyncFailure(job.info.ID)
+	}
+	cli.Log.Debugf("Received history sync (type %s, chunk %d)", historySync.GetSyncType(), historySync.GetChunkOrder())
+	if historySync.GetSyncType() == waProto.HistorySync_PUSH_NAME {
+		go cli.handleHistoricalPushNames(historySync.GetPushnames())
+	} else if len(historySync.GetConversations()) > 0 {
+		go cli.storeHistoricalMessageSecrets(historySync.GetConversations())
+		go cli.storeHistoricalDisappearingTimers(historySync.GetConversations())
+		go cli.indexHistoricalMessages(historySync.GetConversations())
+	}
+	if historySync.GetSyncType() == waProto.HistorySync_ON_DEMAND {
+		cli.handleOnDemandHistorySync(historySync)
+	}
+	cli.dispatchEvent(&events.HistorySync{
+		Data: historySync,
+	})
 }
 
 func (cli *Client) handleAppStateSyncKeyShare(keys *waProto.AppStateSyncKeyShare) {
@@ -451,10 +477,7 @@ func (cli *Client) handleProtocolMessage(info *types.MessageInfo, msg *waProto.M
 	protoMsg := msg.GetProtocolMessage()
 
 	if protoMsg.GetHistorySyncNotification() != nil && info.IsFromMe {
-		cli.historySyncNotifications <- protoMsg.HistorySyncNotification
-		if cli.historySyncHandlerStarted.CompareAndSwap(false, true) {
-			go cli.handleHistorySyncNotificationLoop()
-		}
+		cli.enqueueHistorySync(historySyncJob{info: *info, notif: protoMsg.HistorySyncNotification})
 		go cli.sendProtocolMessageReceipt(info.ID, types.ReceiptTypeHistorySync)
 	}
 
@@ -466,6 +489,10 @@ func (cli *Client) handleProtocolMessage(info *types.MessageInfo, msg *waProto.M
 		go cli.handleAppStateSyncKeyShare(protoMsg.AppStateSyncKeyShare)
 	}
 
+	if protoMsg.GetType() == waProto.ProtocolMessage_EPHEMERAL_SETTING {
+		cli.handleEphemeralSettingMessage(info, protoMsg)
+	}
+
 	if info.Category == "peer" {
 		go cli.sendProtocolMessageReceipt(info.ID, types.ReceiptTypePeerMsg)
 	}
@@ -574,6 +601,19 @@ func (cli *Client) handleDecryptedMessage(info *types.MessageInfo, msg *waProto.
 	cli.processProtocolParts(info, msg)
 	evt := &events.Message{Info: *info, RawMessage: msg, RetryCount: retryCount}
 	cli.dispatchEvent(evt.UnwrapRaw())
+	cli.trackMessage(evt)
+}
+
+// trackMessage updates the poll, reaction, edit history, message expiration and search indexes in the device store based on the given message.
+func (cli *Client) trackMessage(evt *events.Message) {
+	if evt.Message == nil {
+		return
+	}
+	cli.trackPolls(evt)
+	cli.trackReactions(evt)
+	cli.trackEdits(evt)
+	cli.trackMessageExpiration(evt)
+	cli.indexMessage(evt)
 }
 
 func (cli *Client) sendProtocolMessageReceipt(id types.MessageID, msgType types.ReceiptType) {
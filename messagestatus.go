@@ -0,0 +1,237 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sofyan48/whatsmeow/store"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+// memoryMessageStatusSize is the number of messages whose status is kept in memory when the device store
+// doesn't have a MessageStatusStore.
+const memoryMessageStatusSize = 1024
+
+type messageStatusKey struct {
+	Chat types.JID
+	ID   types.MessageID
+}
+
+// memoryMessageStatusStore is a store.MessageStatusStore that keeps the status of the most recent messages in memory.
+type memoryMessageStatusStore struct {
+	statuses map[messageStatusKey]*types.MessageStatusInfo
+	order    [memoryMessageStatusSize]messageStatusKey
+	ptr      int
+	lock     sync.Mutex
+}
+
+var _ store.MessageStatusStore = (*memoryMessageStatusStore)(nil)
+
+func newMemoryMessageStatusStore() *memoryMessageStatusStore {
+	return &memoryMessageStatusStore{
+		statuses: make(map[messageStatusKey]*types.MessageStatusInfo, memoryMessageStatusSize),
+	}
+}
+
+func (mss *memoryMessageStatusStore) PutSentMessage(chat types.JID, id types.MessageID, recipients int, sentAt time.Time) error {
+	mss.lock.Lock()
+	defer mss.lock.Unlock()
+	key := messageStatusKey{chat.ToNonAD(), id}
+	if existing, ok := mss.statuses[key]; ok {
+		existing.Recipients = recipients
+		return nil
+	}
+	if mss.order[mss.ptr].ID != "" {
+		delete(mss.statuses, mss.order[mss.ptr])
+	}
+	mss.order[mss.ptr] = key
+	mss.ptr = (mss.ptr + 1) % memoryMessageStatusSize
+	mss.statuses[key] = &types.MessageStatusInfo{
+		Chat:        key.Chat,
+		ID:          id,
+		SentAt:      sentAt,
+		Recipients:  recipients,
+		DeliveredTo: make(map[types.JID]time.Time),
+		ReadBy:      make(map[types.JID]time.Time),
+		PlayedBy:    make(map[types.JID]time.Time),
+	}
+	return nil
+}
+
+func (mss *memoryMessageStatusStore) PutMessageReceipt(chat types.JID, id types.MessageID, user types.JID, receiptType types.ReceiptType, ts time.Time) error {
+	mss.lock.Lock()
+	defer mss.lock.Unlock()
+	status, ok := mss.statuses[messageStatusKey{chat.ToNonAD(), id}]
+	if !ok {
+		return nil
+	}
+	var target map[types.JID]time.Time
+	switch receiptType {
+	case types.ReceiptTypeDelivered:
+		target = status.DeliveredTo
+	case types.ReceiptTypeRead:
+		target = status.ReadBy
+	case types.ReceiptTypePlayed:
+		target = status.PlayedBy
+	default:
+		return nil
+	}
+	user = user.ToNonAD()
+	if _, alreadyReceived := target[user]; !alreadyReceived {
+		target[user] = ts
+	}
+	return nil
+}
+
+func (mss *memoryMessageStatusStore) GetMessageStatus(chat types.JID, id types.MessageID) (*types.MessageStatusInfo, error) {
+	mss.lock.Lock()
+	defer mss.lock.Unlock()
+	status, ok := mss.statuses[messageStatusKey{chat.ToNonAD(), id}]
+	if !ok {
+		return nil, nil
+	}
+	cloned := *status
+	cloned.DeliveredTo = cloneReceiptMap(status.DeliveredTo)
+	cloned.ReadBy = cloneReceiptMap(status.ReadBy)
+	cloned.PlayedBy = cloneReceiptMap(status.PlayedBy)
+	return &cloned, nil
+}
+
+func cloneReceiptMap(m map[types.JID]time.Time) map[types.JID]time.Time {
+	cloned := make(map[types.JID]time.Time, len(m))
+	for user, ts := range m {
+		cloned[user] = ts
+	}
+	return cloned
+}
+
+func (cli *Client) getMessageStatusStore() store.MessageStatusStore {
+	if cli.Store.MessageStatus != nil {
+		return cli.Store.MessageStatus
+	}
+	return cli.memoryMessageStatus
+}
+
+func isStatusTrackedMessage(evt *events.Message) bool {
+	msg := evt.Message
+	return !evt.IsEdit &&
+		msg.ProtocolMessage == nil &&
+		msg.ReactionMessage == nil &&
+		msg.EncReactionMessage == nil &&
+		msg.PollUpdateMessage == nil
+}
+
+func (cli *Client) countMessageRecipients(chat types.JID) int {
+	var recipients []types.JID
+	var err error
+	switch {
+	case chat.Server == types.DefaultUserServer:
+		return 1
+	case chat.Server == types.GroupServer:
+		recipients, err = cli.getGroupMembers(context.TODO(), chat)
+	case chat.IsBroadcastList():
+		recipients, err = cli.getBroadcastListRecipients(chat)
+	default:
+		return 0
+	}
+	if err != nil {
+		cli.Log.Debugf("Failed to get recipients of %s for message status tracking: %v", chat, err)
+		return 0
+	}
+	ownID := cli.getOwnID()
+	count := 0
+	for _, recipient := range recipients {
+		if recipient.User != ownID.User {
+			count++
+		}
+	}
+	return count
+}
+
+func (cli *Client) trackSentMessage(evt *events.Message) {
+	if !cli.TrackMessageStatus || !isStatusTrackedMessage(evt) {
+		return
+	}
+	statusStore := cli.getMessageStatusStore()
+	err := statusStore.PutSentMessage(evt.Info.Chat, evt.Info.ID, cli.countMessageRecipients(evt.Info.Chat), evt.Info.Timestamp)
+	if err != nil {
+		cli.Log.Warnf("Failed to store sent message %s for status tracking: %v", evt.Info.ID, err)
+		return
+	}
+	status, err := statusStore.GetMessageStatus(evt.Info.Chat, evt.Info.ID)
+	if err != nil {
+		cli.Log.Warnf("Failed to get status of %s after storing it: %v", evt.Info.ID, err)
+		return
+	}
+	cli.dispatchEvent(&events.MessageStatusChanged{
+		Status:         status,
+		PreviousStatus: types.MessageStatusUnknown,
+	})
+}
+
+func (cli *Client) trackReceiptStatus(receipt *events.Receipt) {
+	if !cli.TrackMessageStatus || receipt.IsFromMe {
+		return
+	}
+	// Read receipts imply delivery and played receipts imply both delivery and reading,
+	// so store all the implied receipts too in case the weaker ones never arrive.
+	var receiptTypes []types.ReceiptType
+	switch receipt.Type {
+	case types.ReceiptTypeDelivered:
+		receiptTypes = []types.ReceiptType{types.ReceiptTypeDelivered}
+	case types.ReceiptTypeRead:
+		receiptTypes = []types.ReceiptType{types.ReceiptTypeDelivered, types.ReceiptTypeRead}
+	case types.ReceiptTypePlayed:
+		receiptTypes = []types.ReceiptType{types.ReceiptTypeDelivered, types.ReceiptTypeRead, types.ReceiptTypePlayed}
+	default:
+		return
+	}
+	statusStore := cli.getMessageStatusStore()
+	for _, id := range receipt.MessageIDs {
+		prev, err := statusStore.GetMessageStatus(receipt.Chat, id)
+		if err != nil {
+			cli.Log.Warnf("Failed to get status of %s for receipt from %s: %v", id, receipt.SourceString(), err)
+			continue
+		} else if prev == nil {
+			continue
+		}
+		for _, receiptType := range receiptTypes {
+			err = statusStore.PutMessageReceipt(receipt.Chat, id, receipt.Sender, receiptType, receipt.Timestamp)
+			if err != nil {
+				cli.Log.Warnf("Failed to store %s receipt for %s from %s: %v", receiptType.GoString(), id, receipt.SourceString(), err)
+				break
+			}
+		}
+		current, err := statusStore.GetMessageStatus(receipt.Chat, id)
+		if err != nil {
+			cli.Log.Warnf("Failed to get status of %s after storing receipt: %v", id, err)
+			continue
+		}
+		if current.Status() != prev.Status() ||
+			len(current.DeliveredTo) != len(prev.DeliveredTo) ||
+			len(current.ReadBy) != len(prev.ReadBy) ||
+			len(current.PlayedBy) != len(prev.PlayedBy) {
+			cli.dispatchEvent(&events.MessageStatusChanged{
+				Status:         current,
+				PreviousStatus: prev.Status(),
+			})
+		}
+	}
+}
+
+// GetMessageStatus returns the aggregated delivery status of a message sent by this client.
+//
+// Message statuses are only tracked if TrackMessageStatus is enabled. If the device store has a
+// MessageStatusStore, statuses are persisted there. Otherwise, the statuses of the most recently
+// sent messages are kept in memory. This returns nil if the message isn't being tracked.
+func (cli *Client) GetMessageStatus(chat types.JID, id types.MessageID) (*types.MessageStatusInfo, error) {
+	return cli.getMessageStatusStore().GetMessageStatus(chat, id)
+}
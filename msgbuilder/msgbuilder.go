@@ -0,0 +1,366 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package msgbuilder contains fluent constructors for common WhatsApp message types.
+//
+// For example, to reply to a message with an image:
+//
+//	uploaded, err := cli.Upload(ctx, data, whatsmeow.MediaImage)
+//	msg := msgbuilder.Image(uploaded, "image/jpeg").
+//		Caption("Look at this").
+//		ReplyTo(&evt.Info, evt.Message).
+//		Build()
+//	resp, err := cli.SendMessage(ctx, evt.Info.Chat, msg)
+package msgbuilder
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sofyan48/whatsmeow"
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/types"
+)
+
+// MessageBuilder builds a single message. The modifier methods return the same builder, so calls can be chained.
+//
+// Modifiers that don't apply to the message type (e.g. Caption on a sticker) are ignored.
+type MessageBuilder struct {
+	msg      *waProto.Message
+	viewOnce bool
+}
+
+// Text creates a plain text message.
+func Text(text string) *MessageBuilder {
+	return &MessageBuilder{msg: &waProto.Message{Conversation: proto.String(text)}}
+}
+
+// Image creates an image message from an uploaded file. The upload must have been done with whatsmeow.MediaImage.
+func Image(upload whatsmeow.UploadResponse, mimetype string) *MessageBuilder {
+	return &MessageBuilder{msg: &waProto.Message{ImageMessage: &waProto.ImageMessage{
+		Url:               proto.String(upload.URL),
+		DirectPath:        proto.String(upload.DirectPath),
+		MediaKey:          upload.MediaKey,
+		FileEncSha256:     upload.FileEncSHA256,
+		FileSha256:        upload.FileSHA256,
+		FileLength:        proto.Uint64(upload.FileLength),
+		Mimetype:          proto.String(mimetype),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}}
+}
+
+// Video creates a video message from an uploaded file. The upload must have been done with whatsmeow.MediaVideo.
+func Video(upload whatsmeow.UploadResponse, mimetype string) *MessageBuilder {
+	return &MessageBuilder{msg: &waProto.Message{VideoMessage: &waProto.VideoMessage{
+		Url:               proto.String(upload.URL),
+		DirectPath:        proto.String(upload.DirectPath),
+		MediaKey:          upload.MediaKey,
+		FileEncSha256:     upload.FileEncSHA256,
+		FileSha256:        upload.FileSHA256,
+		FileLength:        proto.Uint64(upload.FileLength),
+		Mimetype:          proto.String(mimetype),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}}
+}
+
+// Audio creates an audio message from an uploaded file. The upload must have been done with whatsmeow.MediaAudio.
+//
+// If ptt is true, the audio will be shown as a voice message.
+func Audio(upload whatsmeow.UploadResponse, mimetype string, ptt bool) *MessageBuilder {
+	return &MessageBuilder{msg: &waProto.Message{AudioMessage: &waProto.AudioMessage{
+		Url:               proto.String(upload.URL),
+		DirectPath:        proto.String(upload.DirectPath),
+		MediaKey:          upload.MediaKey,
+		FileEncSha256:     upload.FileEncSHA256,
+		FileSha256:        upload.FileSHA256,
+		FileLength:        proto.Uint64(upload.FileLength),
+		Mimetype:          proto.String(mimetype),
+		Ptt:               proto.Bool(ptt),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}}
+}
+
+// Document creates a document message from an uploaded file. The upload must have been done with whatsmeow.MediaDocument.
+//
+// If a caption is added, the message will be sent as a document with caption.
+func Document(upload whatsmeow.UploadResponse, mimetype, fileName string) *MessageBuilder {
+	return &MessageBuilder{msg: &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+		Url:               proto.String(upload.URL),
+		DirectPath:        proto.String(upload.DirectPath),
+		MediaKey:          upload.MediaKey,
+		FileEncSha256:     upload.FileEncSHA256,
+		FileSha256:        upload.FileSHA256,
+		FileLength:        proto.Uint64(upload.FileLength),
+		Mimetype:          proto.String(mimetype),
+		FileName:          proto.String(fileName),
+		Title:             proto.String(fileName),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}}
+}
+
+// Sticker creates a sticker message from an uploaded WebP file. The upload must have been done with whatsmeow.MediaImage.
+func Sticker(upload whatsmeow.UploadResponse, animated bool) *MessageBuilder {
+	return &MessageBuilder{msg: &waProto.Message{StickerMessage: &waProto.StickerMessage{
+		Url:               proto.String(upload.URL),
+		DirectPath:        proto.String(upload.DirectPath),
+		MediaKey:          upload.MediaKey,
+		FileEncSha256:     upload.FileEncSHA256,
+		FileSha256:        upload.FileSHA256,
+		FileLength:        proto.Uint64(upload.FileLength),
+		Mimetype:          proto.String("image/webp"),
+		IsAnimated:        proto.Bool(animated),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}}
+}
+
+// Location creates a static location message. The name and address are optional.
+func Location(latitude, longitude float64, name, address string) *MessageBuilder {
+	loc := &waProto.LocationMessage{
+		DegreesLatitude:  proto.Float64(latitude),
+		DegreesLongitude: proto.Float64(longitude),
+	}
+	if name != "" {
+		loc.Name = proto.String(name)
+	}
+	if address != "" {
+		loc.Address = proto.String(address)
+	}
+	return &MessageBuilder{msg: &waProto.Message{LocationMessage: loc}}
+}
+
+// LiveLocation creates a live location message. The sequence number should be incremented for every update.
+func LiveLocation(latitude, longitude float64, sequenceNumber int64) *MessageBuilder {
+	return &MessageBuilder{msg: &waProto.Message{LiveLocationMessage: &waProto.LiveLocationMessage{
+		DegreesLatitude:  proto.Float64(latitude),
+		DegreesLongitude: proto.Float64(longitude),
+		SequenceNumber:   proto.Int64(sequenceNumber),
+	}}}
+}
+
+// Contact creates a contact card message. See VCard for generating the vCard string.
+func Contact(displayName, vcard string) *MessageBuilder {
+	return &MessageBuilder{msg: &waProto.Message{ContactMessage: &waProto.ContactMessage{
+		DisplayName: proto.String(displayName),
+		Vcard:       proto.String(vcard),
+	}}}
+}
+
+// Contacts creates a message containing multiple contact cards.
+func Contacts(displayName string, contacts ...VCard) *MessageBuilder {
+	arr := &waProto.ContactsArrayMessage{
+		DisplayName: proto.String(displayName),
+		Contacts:    make([]*waProto.ContactMessage, len(contacts)),
+	}
+	for i, contact := range contacts {
+		arr.Contacts[i] = &waProto.ContactMessage{
+			DisplayName: proto.String(contact.FullName),
+			Vcard:       proto.String(contact.String()),
+		}
+	}
+	return &MessageBuilder{msg: &waProto.Message{ContactsArrayMessage: arr}}
+}
+
+// Caption sets the caption of an image, video, document or live location message.
+func (b *MessageBuilder) Caption(caption string) *MessageBuilder {
+	switch {
+	case b.msg.ImageMessage != nil:
+		b.msg.ImageMessage.Caption = proto.String(caption)
+	case b.msg.VideoMessage != nil:
+		b.msg.VideoMessage.Caption = proto.String(caption)
+	case b.msg.DocumentMessage != nil:
+		b.msg.DocumentMessage.Caption = proto.String(caption)
+	case b.msg.LiveLocationMessage != nil:
+		b.msg.LiveLocationMessage.Caption = proto.String(caption)
+	}
+	return b
+}
+
+// Thumbnail sets the JPEG thumbnail of a media, location or link preview message.
+func (b *MessageBuilder) Thumbnail(jpeg []byte) *MessageBuilder {
+	switch {
+	case b.msg.ImageMessage != nil:
+		b.msg.ImageMessage.JpegThumbnail = jpeg
+	case b.msg.VideoMessage != nil:
+		b.msg.VideoMessage.JpegThumbnail = jpeg
+	case b.msg.DocumentMessage != nil:
+		b.msg.DocumentMessage.JpegThumbnail = jpeg
+	case b.msg.LocationMessage != nil:
+		b.msg.LocationMessage.JpegThumbnail = jpeg
+	case b.msg.LiveLocationMessage != nil:
+		b.msg.LiveLocationMessage.JpegThumbnail = jpeg
+	case b.msg.ExtendedTextMessage != nil:
+		b.msg.ExtendedTextMessage.JpegThumbnail = jpeg
+	}
+	return b
+}
+
+// Dimensions sets the width and height of an image, video or sticker message.
+func (b *MessageBuilder) Dimensions(width, height uint32) *MessageBuilder {
+	switch {
+	case b.msg.ImageMessage != nil:
+		b.msg.ImageMessage.Width, b.msg.ImageMessage.Height = proto.Uint32(width), proto.Uint32(height)
+	case b.msg.VideoMessage != nil:
+		b.msg.VideoMessage.Width, b.msg.VideoMessage.Height = proto.Uint32(width), proto.Uint32(height)
+	case b.msg.StickerMessage != nil:
+		b.msg.StickerMessage.Width, b.msg.StickerMessage.Height = proto.Uint32(width), proto.Uint32(height)
+	}
+	return b
+}
+
+// Duration sets the length of an audio or video message.
+func (b *MessageBuilder) Duration(dur time.Duration) *MessageBuilder {
+	switch {
+	case b.msg.AudioMessage != nil:
+		b.msg.AudioMessage.Seconds = proto.Uint32(uint32(dur.Seconds()))
+	case b.msg.VideoMessage != nil:
+		b.msg.VideoMessage.Seconds = proto.Uint32(uint32(dur.Seconds()))
+	}
+	return b
+}
+
+// LinkPreview contains the data shown in the preview of a link in a text message.
+type LinkPreview struct {
+	URL         string
+	Title       string
+	Description string
+	// An optional JPEG thumbnail
+	Thumbnail []byte
+}
+
+// LinkPreview adds a link preview to a text message. The URL should also be present in the text.
+func (b *MessageBuilder) LinkPreview(preview LinkPreview) *MessageBuilder {
+	ext := b.extendedText()
+	if ext == nil {
+		return b
+	}
+	ext.MatchedText = proto.String(preview.URL)
+	ext.CanonicalUrl = proto.String(preview.URL)
+	ext.Title = proto.String(preview.Title)
+	ext.Description = proto.String(preview.Description)
+	ext.JpegThumbnail = preview.Thumbnail
+	if len(preview.Thumbnail) > 0 {
+		ext.PreviewType = waProto.ExtendedTextMessage_IMAGE.Enum()
+	} else {
+		ext.PreviewType = waProto.ExtendedTextMessage_NONE.Enum()
+	}
+	return b
+}
+
+// ReplyTo makes the message a reply to the given message.
+//
+// The quoted message is used to render the reply preview, it can be the Message field of the events.Message being replied to.
+func (b *MessageBuilder) ReplyTo(info *types.MessageInfo, quoted *waProto.Message) *MessageBuilder {
+	ctx := b.contextInfo()
+	if ctx == nil {
+		return b
+	}
+	ctx.StanzaId = proto.String(info.ID)
+	ctx.Participant = proto.String(info.Sender.ToNonAD().String())
+	ctx.QuotedMessage = quoted
+	if info.Chat.Server == types.BroadcastServer {
+		ctx.RemoteJid = proto.String(info.Chat.String())
+	}
+	return b
+}
+
+// Mention adds the given users to the list of mentioned users.
+//
+// The text of the message should contain @<phone number> for each mentioned user for them to be rendered.
+func (b *MessageBuilder) Mention(users ...types.JID) *MessageBuilder {
+	ctx := b.contextInfo()
+	if ctx == nil {
+		return b
+	}
+	for _, user := range users {
+		ctx.MentionedJid = append(ctx.MentionedJid, user.ToNonAD().String())
+	}
+	return b
+}
+
+// Forwarded marks the message as forwarded.
+//
+// The forwarding score is the number of times the message has been forwarded,
+// a score of 5 or more will make clients show the message as forwarded many times.
+func (b *MessageBuilder) Forwarded(score uint32) *MessageBuilder {
+	ctx := b.contextInfo()
+	if ctx == nil {
+		return b
+	}
+	ctx.IsForwarded = proto.Bool(true)
+	if score > 0 {
+		ctx.ForwardingScore = proto.Uint32(score)
+	}
+	return b
+}
+
+// ViewOnce marks an image, video or audio message as view-once.
+func (b *MessageBuilder) ViewOnce() *MessageBuilder {
+	switch {
+	case b.msg.ImageMessage != nil:
+		b.msg.ImageMessage.ViewOnce = proto.Bool(true)
+	case b.msg.VideoMessage != nil:
+		b.msg.VideoMessage.ViewOnce = proto.Bool(true)
+	case b.msg.AudioMessage != nil:
+		b.msg.AudioMessage.ViewOnce = proto.Bool(true)
+	default:
+		return b
+	}
+	b.viewOnce = true
+	return b
+}
+
+// Build returns the built message, which can be sent with Client.SendMessage.
+func (b *MessageBuilder) Build() *waProto.Message {
+	msg := b.msg
+	if msg.DocumentMessage != nil && msg.DocumentMessage.GetCaption() != "" {
+		msg = &waProto.Message{DocumentWithCaptionMessage: &waProto.FutureProofMessage{Message: msg}}
+	}
+	if b.viewOnce {
+		msg = &waProto.Message{ViewOnceMessageV2: &waProto.FutureProofMessage{Message: msg}}
+	}
+	return msg
+}
+
+func (b *MessageBuilder) extendedText() *waProto.ExtendedTextMessage {
+	if b.msg.Conversation != nil {
+		b.msg.ExtendedTextMessage = &waProto.ExtendedTextMessage{Text: b.msg.Conversation}
+		b.msg.Conversation = nil
+	}
+	return b.msg.ExtendedTextMessage
+}
+
+func (b *MessageBuilder) contextInfo() *waProto.ContextInfo {
+	var ctx **waProto.ContextInfo
+	switch {
+	case b.msg.Conversation != nil, b.msg.ExtendedTextMessage != nil:
+		ctx = &b.extendedText().ContextInfo
+	case b.msg.ImageMessage != nil:
+		ctx = &b.msg.ImageMessage.ContextInfo
+	case b.msg.VideoMessage != nil:
+		ctx = &b.msg.VideoMessage.ContextInfo
+	case b.msg.AudioMessage != nil:
+		ctx = &b.msg.AudioMessage.ContextInfo
+	case b.msg.DocumentMessage != nil:
+		ctx = &b.msg.DocumentMessage.ContextInfo
+	case b.msg.StickerMessage != nil:
+		ctx = &b.msg.StickerMessage.ContextInfo
+	case b.msg.LocationMessage != nil:
+		ctx = &b.msg.LocationMessage.ContextInfo
+	case b.msg.LiveLocationMessage != nil:
+		ctx = &b.msg.LiveLocationMessage.ContextInfo
+	case b.msg.ContactMessage != nil:
+		ctx = &b.msg.ContactMessage.ContextInfo
+	case b.msg.ContactsArrayMessage != nil:
+		ctx = &b.msg.ContactsArrayMessage.ContextInfo
+	default:
+		return nil
+	}
+	if *ctx == nil {
+		*ctx = &waProto.ContextInfo{}
+	}
+	return *ctx
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package msgbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sofyan48/whatsmeow/types"
+)
+
+// VCard contains the fields of a contact card that WhatsApp clients render.
+type VCard struct {
+	FullName     string
+	Organization string
+	// Phone numbers in international format. Numbers that are on WhatsApp will get a "message" button.
+	PhoneNumbers []string
+}
+
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`)
+
+// String formats the contact as a vCard 3.0 string.
+func (vc VCard) String() string {
+	var buf strings.Builder
+	buf.WriteString("BEGIN:VCARD\nVERSION:3.0\n")
+	_, _ = fmt.Fprintf(&buf, "FN:%s\n", vcardEscaper.Replace(vc.FullName))
+	if vc.Organization != "" {
+		_, _ = fmt.Fprintf(&buf, "ORG:%s\n", vcardEscaper.Replace(vc.Organization))
+	}
+	for _, phone := range vc.PhoneNumbers {
+		waID := strings.TrimPrefix(phone, "+")
+		waID = strings.Map(func(r rune) rune {
+			if r < '0' || r > '9' {
+				return -1
+			}
+			return r
+		}, waID)
+		_, _ = fmt.Fprintf(&buf, "TEL;type=CELL;waid=%s:%s\n", waID, phone)
+	}
+	buf.WriteString("END:VCARD")
+	return buf.String()
+}
+
+// ContactCard creates a contact card message for the given contact.
+func ContactCard(contact VCard) *MessageBuilder {
+	return Contact(contact.FullName, contact.String())
+}
+
+// ContactCardFromJID creates a contact card message for a WhatsApp user.
+func ContactCardFromJID(name string, user types.JID) *MessageBuilder {
+	return ContactCard(VCard{
+		FullName:     name,
+		PhoneNumbers: []string{"+" + user.User},
+	})
+}
@@ -8,12 +8,19 @@ package whatsmeow
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"go.mau.fi/util/jsontime"
+	"google.golang.org/protobuf/proto"
+
 	waBinary "github.com/sofyan48/whatsmeow/binary"
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
 	types "github.com/sofyan48/whatsmeow/types"
 )
 
@@ -115,6 +122,12 @@ const (
 	mutationCreateNewsletter       = "6234210096708695"
 	mutationUnfollowNewsletter     = "6392786840836363"
 	mutationFollowNewsletter       = "9926858900719341"
+	mutationDeleteNewsletter       = "8316537688363079" // variables -> {newsletter_id}, output: xwa2_newsletter_delete_v2
+	mutationDemoteNewsletterAdmin  = "6551828931592903" // variables -> {newsletter_id, user_id}, output: xwa2_newsletter_admin_demote
+	mutationChangeNewsletterOwner  = "7341777602580933" // variables -> {newsletter_id, user_id}, output: xwa2_newsletter_change_owner
+	mutationCreateAdminInvite      = "6826078034173770" // variables -> {newsletter_id, user_id}, output: xwa2_newsletter_admin_invite_create -> invite_expiration_time
+	mutationRevokeAdminInvite      = "6111171595650958" // variables -> {newsletter_id, user_id}, output: xwa2_newsletter_admin_invite_revoke
+	mutationAcceptAdminInvite      = "7292354640794756" // variables -> {newsletter_id}, output: xwa2_newsletter_admin_invite_accept
 )
 
 func (cli *Client) sendMexIQ(ctx context.Context, queryID string, variables any) (json.RawMessage, error) {
@@ -371,3 +384,266 @@ func (cli *Client) GetNewsletterMessageUpdates(jid types.JID, params *GetNewslet
 	}
 	return cli.parseNewsletterMessages(&messages), nil
 }
+
+type respUpdateNewsletter struct {
+	Newsletter *types.NewsletterMetadata `json:"xwa2_newsletter_update"`
+}
+
+func (cli *Client) updateNewsletter(jid types.JID, updates map[string]any) (*types.NewsletterMetadata, error) {
+	resp, err := cli.sendMexIQ(context.TODO(), mutationUpdateNewsletter, map[string]any{
+		"newsletter_id": jid.String(),
+		"updates":       updates,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var respData respUpdateNewsletter
+	err = json.Unmarshal(resp, &respData)
+	if err != nil {
+		return nil, err
+	}
+	return respData.Newsletter, nil
+}
+
+// SetNewsletterName changes the name of a WhatsApp channel. You must be an admin of the channel.
+func (cli *Client) SetNewsletterName(jid types.JID, name string) (*types.NewsletterMetadata, error) {
+	return cli.updateNewsletter(jid, map[string]any{"name": name})
+}
+
+// SetNewsletterDescription changes the description of a WhatsApp channel. You must be an admin of the channel.
+func (cli *Client) SetNewsletterDescription(jid types.JID, description string) (*types.NewsletterMetadata, error) {
+	return cli.updateNewsletter(jid, map[string]any{"description": description})
+}
+
+// SetNewsletterPhoto changes the picture of a WhatsApp channel. You must be an admin of the channel.
+//
+// The picture must be a JPEG. To remove the picture, pass nil.
+func (cli *Client) SetNewsletterPhoto(jid types.JID, avatar []byte) (*types.NewsletterMetadata, error) {
+	return cli.updateNewsletter(jid, map[string]any{"picture": base64.StdEncoding.EncodeToString(avatar)})
+}
+
+// SetNewsletterReactionsMode changes which reactions subscribers can send to messages in a WhatsApp channel.
+func (cli *Client) SetNewsletterReactionsMode(jid types.JID, mode types.NewsletterReactionsMode) (*types.NewsletterMetadata, error) {
+	return cli.updateNewsletter(jid, map[string]any{
+		"settings": types.NewsletterSettings{
+			ReactionCodes: types.NewsletterReactionSettings{Value: mode},
+		},
+	})
+}
+
+// DeleteNewsletter permanently deletes a WhatsApp channel. You must be the owner of the channel.
+func (cli *Client) DeleteNewsletter(jid types.JID) error {
+	_, err := cli.sendMexIQ(context.TODO(), mutationDeleteNewsletter, map[string]any{
+		"newsletter_id": jid.String(),
+	})
+	return err
+}
+
+type respCreateNewsletterAdminInvite struct {
+	Invite struct {
+		ExpirationTime jsontime.UnixString `json:"invite_expiration_time"`
+	} `json:"xwa2_newsletter_admin_invite_create"`
+}
+
+// NewsletterInviteAdmin invites the given user to become an admin of a WhatsApp channel.
+//
+// The invite is created on the server and then sent to the user as a NewsletterAdminInviteMessage,
+// the user becomes an admin when they accept it (see NewsletterAcceptAdminInvite). The caption is optional.
+func (cli *Client) NewsletterInviteAdmin(ctx context.Context, jid, user types.JID, caption string) (time.Time, error) {
+	resp, err := cli.sendMexIQ(ctx, mutationCreateAdminInvite, map[string]any{
+		"newsletter_id": jid.String(),
+		"user_id":       user.ToNonAD().String(),
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	var respData respCreateNewsletterAdminInvite
+	err = json.Unmarshal(resp, &respData)
+	if err != nil {
+		return time.Time{}, err
+	}
+	expiration := respData.Invite.ExpirationTime.Time
+	meta, err := cli.GetNewsletterInfo(jid)
+	if err != nil {
+		return expiration, fmt.Errorf("failed to get newsletter info for invite message: %w", err)
+	}
+	msg := &waProto.NewsletterAdminInviteMessage{
+		NewsletterJid:  proto.String(jid.String()),
+		NewsletterName: proto.String(meta.ThreadMeta.Name.Text),
+	}
+	if caption != "" {
+		msg.Caption = proto.String(caption)
+	}
+	if !expiration.IsZero() {
+		msg.InviteExpiration = proto.Int64(expiration.Unix())
+	}
+	_, err = cli.SendMessage(ctx, user.ToNonAD(), &waProto.Message{NewsletterAdminInviteMessage: msg})
+	if err != nil {
+		return expiration, fmt.Errorf("failed to send invite message: %w", err)
+	}
+	return expiration, nil
+}
+
+// NewsletterRevokeAdminInvite revokes a pending admin invite sent with NewsletterInviteAdmin.
+func (cli *Client) NewsletterRevokeAdminInvite(jid, user types.JID) error {
+	_, err := cli.sendMexIQ(context.TODO(), mutationRevokeAdminInvite, map[string]any{
+		"newsletter_id": jid.String(),
+		"user_id":       user.ToNonAD().String(),
+	})
+	return err
+}
+
+// NewsletterAcceptAdminInvite accepts an admin invite to a WhatsApp channel, which promotes the current user to an admin.
+func (cli *Client) NewsletterAcceptAdminInvite(jid types.JID) error {
+	_, err := cli.sendMexIQ(context.TODO(), mutationAcceptAdminInvite, map[string]any{
+		"newsletter_id": jid.String(),
+	})
+	return err
+}
+
+// NewsletterPromoteToOwner transfers the ownership of a WhatsApp channel to the given admin.
+// The current owner will be demoted to an admin.
+func (cli *Client) NewsletterPromoteToOwner(jid, user types.JID) error {
+	_, err := cli.sendMexIQ(context.TODO(), mutationChangeNewsletterOwner, map[string]any{
+		"newsletter_id": jid.String(),
+		"user_id":       user.ToNonAD().String(),
+	})
+	return err
+}
+
+// NewsletterDemoteAdmin removes the admin status of the given user in a WhatsApp channel.
+func (cli *Client) NewsletterDemoteAdmin(jid, user types.JID) error {
+	_, err := cli.sendMexIQ(context.TODO(), mutationDemoteNewsletterAdmin, map[string]any{
+		"newsletter_id": jid.String(),
+		"user_id":       user.ToNonAD().String(),
+	})
+	return err
+}
+
+func (cli *Client) getNewsletterMessage(jid types.JID, serverID types.MessageServerID) (*types.NewsletterMessage, error) {
+	messages, err := cli.GetNewsletterMessages(jid, &GetNewsletterMessagesParams{
+		Count:  1,
+		Before: serverID + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range messages {
+		if msg.MessageServerID == serverID {
+			return msg, nil
+		}
+	}
+	return nil, ErrNewsletterMessageNotFound
+}
+
+// GetNewsletterMessageStats gets the view count and reaction counts of a single message in a WhatsApp channel.
+func (cli *Client) GetNewsletterMessageStats(jid types.JID, serverID types.MessageServerID) (*types.NewsletterMessage, error) {
+	return cli.getNewsletterMessage(jid, serverID)
+}
+
+func (cli *Client) getNewsletterMessageID(jid types.JID, serverID types.MessageServerID) (types.MessageID, error) {
+	msg, err := cli.getNewsletterMessage(jid, serverID)
+	if err != nil {
+		return "", err
+	} else if msg.MessageID == "" {
+		return "", &ElementMissingError{Tag: "id", In: "newsletter message"}
+	}
+	return msg.MessageID, nil
+}
+
+// NewsletterEditMessage edits a message in a WhatsApp channel using its server ID.
+//
+// If you have the original message ID, you can also use BuildEdit and SendMessage directly.
+func (cli *Client) NewsletterEditMessage(ctx context.Context, jid types.JID, serverID types.MessageServerID, newContent *waProto.Message) (SendResponse, error) {
+	id, err := cli.getNewsletterMessageID(jid, serverID)
+	if err != nil {
+		return SendResponse{}, fmt.Errorf("failed to find message ID: %w", err)
+	}
+	return cli.SendMessage(ctx, jid, cli.BuildEdit(jid, id, newContent))
+}
+
+// NewsletterRevokeMessage deletes a message in a WhatsApp channel using its server ID.
+//
+// If you have the original message ID, you can also use BuildRevoke and SendMessage directly.
+func (cli *Client) NewsletterRevokeMessage(ctx context.Context, jid types.JID, serverID types.MessageServerID) (SendResponse, error) {
+	id, err := cli.getNewsletterMessageID(jid, serverID)
+	if err != nil {
+		return SendResponse{}, fmt.Errorf("failed to find message ID: %w", err)
+	}
+	return cli.SendMessage(ctx, jid, cli.BuildRevoke(jid, types.EmptyJID, id))
+}
+
+// BuildNewsletterPollCreation builds a poll creation message that can be sent to a WhatsApp channel using Client.SendMessage.
+//
+// Unlike normal polls (see BuildPollCreation), channel polls are not encrypted and votes are only available as
+// aggregated counts, which can be fetched with GetNewsletterPollResults.
+func (cli *Client) BuildNewsletterPollCreation(name string, optionNames []string, selectableOptionCount int) *waProto.Message {
+	if selectableOptionCount < 0 || selectableOptionCount > len(optionNames) {
+		selectableOptionCount = 0
+	}
+	options := make([]*waProto.PollCreationMessage_Option, len(optionNames))
+	for i, option := range optionNames {
+		options[i] = &waProto.PollCreationMessage_Option{OptionName: proto.String(option)}
+	}
+	return &waProto.Message{
+		PollCreationMessageV3: &waProto.PollCreationMessage{
+			Name:                   proto.String(name),
+			Options:                options,
+			SelectableOptionsCount: proto.Uint32(uint32(selectableOptionCount)),
+		},
+	}
+}
+
+// normalizePollOptionHash converts a poll option hash from a newsletter update into the hex form used in NewsletterMessage.PollVoteCounts.
+func normalizePollOptionHash(hash string) string {
+	if decoded, err := base64.StdEncoding.DecodeString(hash); err == nil && len(decoded) == sha256.Size {
+		return hex.EncodeToString(decoded)
+	}
+	return strings.ToLower(hash)
+}
+
+// GetNewsletterPollResults gets the aggregated results of a poll in a WhatsApp channel.
+//
+// The poll itself is fetched with GetNewsletterMessages and the vote counts with GetNewsletterMessageUpdates.
+func (cli *Client) GetNewsletterPollResults(jid types.JID, serverID types.MessageServerID) (*types.NewsletterPollResults, error) {
+	msg, err := cli.getNewsletterMessage(jid, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll message: %w", err)
+	}
+	poll := msg.Message.GetPollCreationMessageV3()
+	if poll == nil {
+		poll = msg.Message.GetPollCreationMessage()
+	}
+	if poll == nil {
+		return nil, ErrNotNewsletterPoll
+	}
+	voteCounts := msg.PollVoteCounts
+	updates, err := cli.GetNewsletterMessageUpdates(jid, &GetNewsletterUpdatesParams{
+		Count: 1,
+		After: serverID - 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll updates: %w", err)
+	}
+	for _, update := range updates {
+		if update.MessageServerID == serverID && update.PollVoteCounts != nil {
+			voteCounts = update.PollVoteCounts
+		}
+	}
+	results := &types.NewsletterPollResults{
+		MessageServerID: serverID,
+		Name:            poll.GetName(),
+		Options:         make([]types.NewsletterPollOption, len(poll.GetOptions())),
+	}
+	for i, option := range poll.GetOptions() {
+		hash := sha256.Sum256([]byte(option.GetOptionName()))
+		votes := voteCounts[hex.EncodeToString(hash[:])]
+		results.Options[i] = types.NewsletterPollOption{
+			Name:  option.GetOptionName(),
+			Hash:  hash[:],
+			Votes: votes,
+		}
+		results.TotalVotes += votes
+	}
+	return results, nil
+}
@@ -31,6 +31,7 @@ func (cli *Client) handleEncryptNotification(node *waBinary.Node) {
 			return
 		}
 		cli.Log.Infof("Got prekey count from server: %s", node.XMLString())
+		cli.checkPreKeyDepletion(otksLeft)
 		if otksLeft < MinPreKeyCount {
 			cli.uploadPreKeys()
 		}
@@ -45,7 +46,7 @@ func (cli *Client) handleEncryptNotification(node *waBinary.Node) {
 			cli.Log.Warnf("Failed to delete all sessions of %s from store after identity change: %v", from, err)
 		}
 		ts := node.AttrGetter().UnixTime("t")
-		cli.dispatchEvent(&events.IdentityChange{JID: from, Timestamp: ts})
+		cli.dispatchEvent(&events.IdentityChange{JID: from, Timestamp: ts, WasVerified: cli.isIdentityVerified(from)})
 	} else {
 		cli.Log.Debugf("Got unknown encryption notification from server: %s", node.XMLString())
 	}
@@ -104,6 +105,7 @@ func (cli *Client) handleDeviceNotification(node *waBinary.Node) {
 		cli.Log.Debugf("No device list cached for %s, ignoring device list notification", from)
 		return
 	}
+	oldDevices := append([]types.JID{}, cached.devices...)
 	cachedParticipantHash := participantListHashV2(cached.devices)
 	for _, child := range node.GetChildren() {
 		if child.Tag != "add" && child.Tag != "remove" {
@@ -135,6 +137,11 @@ func (cli *Client) handleDeviceNotification(node *waBinary.Node) {
 			delete(cli.userDevicesCache, from)
 		}
 	}
+	if from.ToNonAD() == cli.getOwnID().ToNonAD() {
+		if updated, ok := cli.userDevicesCache[from]; ok {
+			cli.dispatchOwnDevicesChanged(oldDevices, true, updated.devices)
+		}
+	}
 }
 
 func (cli *Client) handleFBDeviceNotification(node *waBinary.Node) {
@@ -153,13 +160,6 @@ func (cli *Client) handleOwnDevicesNotification(node *waBinary.Node) {
 		cli.Log.Debugf("Ignoring own device change notification, session was deleted")
 		return
 	}
-	cached, ok := cli.userDevicesCache[ownID]
-	if !ok {
-		cli.Log.Debugf("Ignoring own device change notification, device list not cached")
-		return
-	}
-	oldHash := participantListHashV2(cached.devices)
-	expectedNewHash := node.AttrGetter().String("dhash")
 	var newDeviceList []types.JID
 	for _, child := range node.GetChildren() {
 		jid := child.AttrGetter().JID("jid")
@@ -167,14 +167,22 @@ func (cli *Client) handleOwnDevicesNotification(node *waBinary.Node) {
 			newDeviceList = append(newDeviceList, jid)
 		}
 	}
+	cached, ok := cli.userDevicesCache[ownID]
+	expectedNewHash := node.AttrGetter().String("dhash")
 	newHash := participantListHashV2(newDeviceList)
 	if newHash != expectedNewHash {
-		cli.Log.Debugf("Received own device list change notification %s -> %s, but expected hash was %s", oldHash, newHash, expectedNewHash)
+		cli.Log.Debugf("Received own device list change notification with hash %s, but expected hash was %s", newHash, expectedNewHash)
 		delete(cli.userDevicesCache, ownID)
-	} else {
-		cli.Log.Debugf("Received own device list change notification %s -> %s", oldHash, newHash)
-		cli.userDevicesCache[ownID] = deviceCache{devices: newDeviceList, dhash: expectedNewHash}
+		return
+	}
+	cli.dispatchOwnDevicesChanged(cached.devices, ok, newDeviceList)
+	if !ok {
+		cli.Log.Debugf("Ignoring own device change notification, device list not cached")
+		return
 	}
+	oldHash := participantListHashV2(cached.devices)
+	cli.Log.Debugf("Received own device list change notification %s -> %s", oldHash, newHash)
+	cli.userDevicesCache[ownID] = deviceCache{devices: newDeviceList, dhash: expectedNewHash}
 }
 
 func (cli *Client) handleBlocklist(node *waBinary.Node) {
@@ -260,6 +268,9 @@ func (cli *Client) handlePrivacyTokenNotification(node *waBinary.Node) {
 				cli.Log.Errorf("Failed to save privacy token from %s: %v", sender, err)
 			} else {
 				cli.Log.Debugf("Stored privacy token from %s (ts: %v)", sender, timestamp)
+				if cli.isSubscribedToPresence(sender) {
+					go cli.resubscribeWithNewToken(sender)
+				}
 			}
 		}
 	}
@@ -272,8 +283,10 @@ func (cli *Client) parseNewsletterMessages(node *waBinary.Node) []*types.Newslet
 		if child.Tag != "message" {
 			continue
 		}
+		ag := child.AttrGetter()
 		msg := types.NewsletterMessage{
-			MessageServerID: child.AttrGetter().Int("server_id"),
+			MessageServerID: ag.Int("server_id"),
+			MessageID:       types.MessageID(ag.OptionalString("id")),
 			ViewsCount:      0,
 			ReactionCounts:  nil,
 		}
@@ -297,6 +310,12 @@ func (cli *Client) parseNewsletterMessages(node *waBinary.Node) []*types.Newslet
 					rag := reaction.AttrGetter()
 					msg.ReactionCounts[rag.String("code")] = rag.Int("count")
 				}
+			case "votes":
+				msg.PollVoteCounts = make(map[string]int)
+				for _, vote := range subchild.GetChildren() {
+					vag := vote.AttrGetter()
+					msg.PollVoteCounts[normalizePollOptionHash(vag.String("hash"))] = vag.Int("count")
+				}
 			}
 		}
 		output = append(output, &msg)
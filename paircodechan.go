@@ -0,0 +1,231 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sofyan48/whatsmeow/types/events"
+	waLog "github.com/sofyan48/whatsmeow/util/log"
+)
+
+type PairCodeChannelItem struct {
+	// The type of event, "code" for new pairing codes (see Code field) and "error" for pairing errors (see Error) field.
+	// For non-code/error events, you can just compare the whole item to the event variables (like PairCodeChannelSuccess).
+	Event string
+	// If the item is a pair error, then this field contains the error message.
+	Error error
+	// If the item is a new code, then this field contains the code formatted as XXXX-XXXX.
+	Code string
+	// The timeout after which the code expires and a new one will be generated.
+	Timeout time.Duration
+}
+
+const PairCodeChannelEventCode = "code"
+const PairCodeChannelEventError = "error"
+
+var (
+	// PairCodeExpiry is how long a pairing code is used before a new login websocket is opened and a new code is generated.
+	PairCodeExpiry = 160 * time.Second
+	// PairCodeMaxCodes is the number of pairing codes GetPairCodeChannel generates before giving up with PairCodeChannelTimeout.
+	PairCodeMaxCodes = 5
+)
+
+// Possible items in the pair code channel. In addition to these, `code` and `error` events may be emitted.
+var (
+	// PairCodeChannelSuccess is emitted from GetPairCodeChannel when the pairing is successful.
+	PairCodeChannelSuccess = PairCodeChannelItem{Event: "success"}
+	// PairCodeChannelExpired is emitted from GetPairCodeChannel when the previous code expired.
+	// It will be followed by a new code, unless the maximum number of codes has been reached.
+	PairCodeChannelExpired = PairCodeChannelItem{Event: "expired"}
+	// PairCodeChannelTimeout is emitted from GetPairCodeChannel if PairCodeMaxCodes codes expired without the pairing succeeding.
+	PairCodeChannelTimeout = PairCodeChannelItem{Event: "timeout"}
+	// PairCodeChannelErrUnexpectedEvent is emitted from GetPairCodeChannel if an unexpected connection event is received,
+	// as that likely means that the pairing has already happened before the channel was set up.
+	PairCodeChannelErrUnexpectedEvent = PairCodeChannelItem{Event: "err-unexpected-state"}
+	// PairCodeChannelClientOutdated is emitted from GetPairCodeChannel if events.ClientOutdated is received.
+	PairCodeChannelClientOutdated = PairCodeChannelItem{Event: "err-client-outdated"}
+)
+
+type pairCodeChannel struct {
+	sync.Mutex
+	cli         *Client
+	log         waLog.Logger
+	ctx         context.Context
+	handlerID   uint32
+	closed      uint32
+	output      chan PairCodeChannelItem
+	stopExpiry  chan struct{}
+	codeCount   int
+	phone       string
+	clientType  PairClientType
+	displayName string
+}
+
+func (pcc *pairCodeChannel) close(item *PairCodeChannelItem, disconnect bool) {
+	pcc.Lock()
+	if !atomic.CompareAndSwapUint32(&pcc.closed, 0, 1) {
+		pcc.Unlock()
+		if item != nil {
+			pcc.log.Debugf("Got status %+v, but channel is already closed", *item)
+		}
+		return
+	}
+	if pcc.stopExpiry != nil {
+		close(pcc.stopExpiry)
+		pcc.stopExpiry = nil
+	}
+	if item != nil {
+		pcc.log.Debugf("Closing channel with status %+v", *item)
+		pcc.output <- *item
+	}
+	close(pcc.output)
+	pcc.Unlock()
+	// Has to be done in background because otherwise there's a deadlock with eventHandlersLock
+	go pcc.cli.RemoveEventHandler(pcc.handlerID)
+	if disconnect {
+		pcc.cli.Disconnect()
+	}
+}
+
+func (pcc *pairCodeChannel) emit(item PairCodeChannelItem) bool {
+	pcc.Lock()
+	defer pcc.Unlock()
+	if atomic.LoadUint32(&pcc.closed) == 1 {
+		return false
+	}
+	select {
+	case pcc.output <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+func (pcc *pairCodeChannel) generateCode() {
+	pcc.Lock()
+	if atomic.LoadUint32(&pcc.closed) == 1 {
+		pcc.Unlock()
+		return
+	} else if pcc.codeCount >= PairCodeMaxCodes {
+		pcc.Unlock()
+		pcc.log.Debugf("Generated %d codes without successful pairing, giving up", pcc.codeCount)
+		pcc.close(&PairCodeChannelTimeout, true)
+		return
+	}
+	pcc.codeCount++
+	stopExpiry := make(chan struct{})
+	pcc.stopExpiry = stopExpiry
+	pcc.Unlock()
+
+	code, err := pcc.cli.PairPhone(pcc.phone, true, pcc.clientType, pcc.displayName)
+	if err != nil {
+		pcc.log.Debugf("Failed to generate pairing code: %v", err)
+		pcc.close(&PairCodeChannelItem{Event: PairCodeChannelEventError, Error: err}, true)
+		return
+	}
+	pcc.log.Debugf("Emitting pairing code %s", code)
+	if !pcc.emit(PairCodeChannelItem{Event: PairCodeChannelEventCode, Code: code, Timeout: PairCodeExpiry}) {
+		pcc.log.Debugf("Output channel didn't accept code, closing channel")
+		pcc.close(nil, true)
+		return
+	}
+	select {
+	case <-time.After(PairCodeExpiry):
+		pcc.expire()
+	case <-stopExpiry:
+	case <-pcc.ctx.Done():
+		pcc.log.Debugf("Context is done, closing pairing code channel")
+		pcc.close(nil, true)
+	}
+}
+
+func (pcc *pairCodeChannel) expire() {
+	pcc.Lock()
+	pcc.stopExpiry = nil
+	pcc.Unlock()
+	if !pcc.emit(PairCodeChannelExpired) {
+		return
+	}
+	pcc.log.Debugf("Pairing code expired, reconnecting to generate a new one")
+	pcc.cli.Disconnect()
+	err := pcc.cli.Connect()
+	if err != nil {
+		pcc.close(&PairCodeChannelItem{Event: PairCodeChannelEventError, Error: err}, false)
+	}
+}
+
+func (pcc *pairCodeChannel) handleEvent(rawEvt interface{}) {
+	if atomic.LoadUint32(&pcc.closed) == 1 {
+		pcc.log.Debugf("Dropping event of type %T, channel is closed", rawEvt)
+		return
+	}
+	var outputType PairCodeChannelItem
+	switch evt := rawEvt.(type) {
+	case *events.QR:
+		pcc.log.Debugf("Login websocket is ready, generating pairing code")
+		go pcc.generateCode()
+		return
+	case *events.ClientOutdated:
+		outputType = PairCodeChannelClientOutdated
+	case *events.PairSuccess:
+		outputType = PairCodeChannelSuccess
+	case *events.PairError:
+		outputType = PairCodeChannelItem{
+			Event: PairCodeChannelEventError,
+			Error: evt.Error,
+		}
+	case *events.Disconnected:
+		// The server closed the login websocket, which means the current code expired
+		pcc.Lock()
+		stopExpiry := pcc.stopExpiry
+		pcc.stopExpiry = nil
+		pcc.Unlock()
+		if stopExpiry != nil {
+			close(stopExpiry)
+		}
+		go pcc.expire()
+		return
+	case *events.Connected, *events.ConnectFailure, *events.LoggedOut, *events.TemporaryBan:
+		outputType = PairCodeChannelErrUnexpectedEvent
+	default:
+		return
+	}
+	pcc.close(&outputType, false)
+}
+
+// GetPairCodeChannel returns a channel that outputs a phone pairing code (see PairPhone) and automatically generates
+// a new one when the previous one expires.
+//
+// Like GetQRChannel, this must be called *before* Connect(). The code is generated as soon as the login websocket
+// is ready. When a code expires, an "expired" item is emitted and a new code is generated on a new websocket,
+// up to PairCodeMaxCodes times.
+//
+// The last value to be emitted will be a special event like "success", "timeout" or another error code
+// depending on the result of the pairing. The channel will be closed immediately after one of those.
+func (cli *Client) GetPairCodeChannel(ctx context.Context, phone string, clientType PairClientType, clientDisplayName string) (<-chan PairCodeChannelItem, error) {
+	if cli.IsConnected() {
+		return nil, ErrQRAlreadyConnected
+	} else if cli.Store.ID != nil {
+		return nil, ErrQRStoreContainsID
+	}
+	ch := make(chan PairCodeChannelItem, 8)
+	pcc := &pairCodeChannel{
+		output:      ch,
+		cli:         cli,
+		log:         cli.Log.Sub("PairCodeChannel"),
+		ctx:         ctx,
+		phone:       phone,
+		clientType:  clientType,
+		displayName: clientDisplayName,
+	}
+	pcc.handlerID = cli.AddEventHandler(pcc.handleEvent)
+	return ch, nil
+}
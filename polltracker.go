@@ -0,0 +1,154 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+func getPollCreationMessage(msg *waProto.Message) *waProto.PollCreationMessage {
+	if poll := msg.GetPollCreationMessage(); poll != nil {
+		return poll
+	} else if poll = msg.GetPollCreationMessageV2(); poll != nil {
+		return poll
+	}
+	return msg.GetPollCreationMessageV3()
+}
+
+// trackPolls stores poll creations and votes and dispatches PollUpdated events.
+func (cli *Client) trackPolls(evt *events.Message) {
+	if cli.Store.Polls == nil {
+		return
+	}
+	if poll := getPollCreationMessage(evt.Message); poll != nil {
+		cli.storePollCreation(&evt.Info, poll)
+	} else if evt.Message.GetPollUpdateMessage() != nil {
+		cli.storePollVote(evt)
+	}
+}
+
+func (cli *Client) storePollCreation(info *types.MessageInfo, msg *waProto.PollCreationMessage) {
+	poll := types.PollInfo{
+		Chat:                   info.Chat,
+		Sender:                 info.Sender,
+		ID:                     info.ID,
+		Timestamp:              info.Timestamp,
+		Name:                   msg.GetName(),
+		SelectableOptionsCount: int(msg.GetSelectableOptionsCount()),
+		Options:                make([]types.PollOption, len(msg.GetOptions())),
+	}
+	for i, option := range msg.GetOptions() {
+		hash := sha256.Sum256([]byte(option.GetOptionName()))
+		poll.Options[i] = types.PollOption{Name: option.GetOptionName(), Hash: hash[:]}
+	}
+	err := cli.Store.Polls.PutPoll(poll)
+	if err != nil {
+		cli.Log.Warnf("Failed to store poll %s in %s: %v", info.ID, info.Chat, err)
+	}
+}
+
+func (cli *Client) storePollVote(evt *events.Message) {
+	pollUpdate := evt.Message.GetPollUpdateMessage()
+	pollID := types.MessageID(pollUpdate.GetPollCreationMessageKey().GetId())
+	decrypted, err := cli.DecryptPollVote(evt)
+	if err != nil {
+		cli.Log.Warnf("Failed to decrypt vote %s from %s for poll %s: %v", evt.Info.ID, evt.Info.SourceString(), pollID, err)
+		return
+	}
+	vote := types.PollVote{
+		Voter:           evt.Info.Sender.ToNonAD(),
+		SelectedOptions: decrypted.GetSelectedOptions(),
+		Timestamp:       evt.Info.Timestamp,
+	}
+	if pollUpdate.SenderTimestampMs != nil {
+		vote.Timestamp = time.UnixMilli(pollUpdate.GetSenderTimestampMs())
+	}
+	err = cli.Store.Polls.PutPollVote(evt.Info.Chat, pollID, vote)
+	if err != nil {
+		cli.Log.Warnf("Failed to store vote from %s for poll %s: %v", evt.Info.SourceString(), pollID, err)
+		return
+	}
+	results, err := cli.GetPollResults(evt.Info.Chat, pollID)
+	if err != nil {
+		cli.Log.Warnf("Failed to get results of poll %s after storing vote: %v", pollID, err)
+		return
+	} else if results == nil {
+		cli.Log.Debugf("Stored vote from %s for unknown poll %s", evt.Info.SourceString(), pollID)
+		return
+	}
+	cli.dispatchEvent(&events.PollUpdated{
+		Chat:            evt.Info.Chat,
+		PollID:          pollID,
+		Vote:            vote,
+		SelectedOptions: resolvePollOptionNames(&results.Poll, vote.SelectedOptions),
+		Results:         results,
+	})
+}
+
+func resolvePollOptionNames(poll *types.PollInfo, hashes [][]byte) []string {
+	names := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		for _, option := range poll.Options {
+			if bytes.Equal(option.Hash, hash) {
+				names = append(names, option.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// GetPollResults returns the current results of a poll that was tracked in the device store.
+//
+// Polls are tracked automatically when they're received or sent through this client,
+// and votes are decrypted and stored as they come in. If a user changes their vote,
+// only the latest one is counted. This returns nil if the poll isn't known.
+func (cli *Client) GetPollResults(chat types.JID, pollID types.MessageID) (*types.PollResults, error) {
+	if cli.Store.Polls == nil {
+		return nil, fmt.Errorf("poll store is not available")
+	}
+	poll, err := cli.Store.Polls.GetPoll(chat, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	} else if poll == nil {
+		return nil, nil
+	}
+	votes, err := cli.Store.Polls.GetPollVotes(chat, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll votes: %w", err)
+	}
+	results := &types.PollResults{
+		Poll:    *poll,
+		Options: make([]types.PollOptionResult, len(poll.Options)),
+	}
+	for i, option := range poll.Options {
+		results.Options[i].PollOption = option
+	}
+	for _, vote := range votes {
+		counted := false
+		for _, hash := range vote.SelectedOptions {
+			for i := range results.Options {
+				if bytes.Equal(results.Options[i].Hash, hash) {
+					results.Options[i].Voters = append(results.Options[i].Voters, vote.Voter)
+					counted = true
+					break
+				}
+			}
+		}
+		if counted {
+			results.TotalVoters++
+		}
+	}
+	return results, nil
+}
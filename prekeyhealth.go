@@ -0,0 +1,208 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	waBinary "github.com/sofyan48/whatsmeow/binary"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+var (
+	// SignedPreKeyRotationInterval is how often the signed prekey is rotated automatically. Set to zero to disable rotation.
+	SignedPreKeyRotationInterval = 7 * 24 * time.Hour
+	// SignedPreKeyGracePeriod is how long previous signed prekeys are kept after rotation,
+	// so that sessions started with a bundle fetched before the rotation can still be decrypted.
+	SignedPreKeyGracePeriod = 30 * 24 * time.Hour
+	// UploadedPreKeyMaxAge is how long uploaded one-time prekeys are kept locally before they're considered
+	// abandoned and pruned. Zero (the default) disables pruning.
+	//
+	// The server keeps handing out uploaded prekeys until they're consumed, and there's no way to know which
+	// ones it still has, so pruning may make the first message from a new contact undecryptable.
+	UploadedPreKeyMaxAge time.Duration
+	// preKeyMaintenanceInterval is how often the prekey maintenance loop runs.
+	preKeyMaintenanceInterval = 6 * time.Hour
+)
+
+// signedPreKeyIDMask is the maximum signed prekey ID, as IDs are sent as 3 bytes.
+const signedPreKeyIDMask = 0xffffff
+
+// PreKeyHealth contains statistics about the prekeys of the client.
+type PreKeyHealth struct {
+	// The number of one-time prekeys the server has available for other users.
+	ServerCount int
+	// The number of one-time prekeys that have been uploaded and not used or pruned yet.
+	LocalUploaded int
+	// The number of one-time prekeys generated locally, but not uploaded yet.
+	LocalUnuploaded int
+
+	SignedPreKeyID        uint32
+	SignedPreKeyCreatedAt time.Time
+	// The time when the signed prekey will be rotated next. Zero if rotation is disabled.
+	NextRotation time.Time
+}
+
+func (cli *Client) checkPreKeyDepletion(serverCount int) {
+	if serverCount < MinPreKeyCount {
+		cli.dispatchEvent(&events.PreKeysLow{ServerCount: serverCount, MinCount: MinPreKeyCount})
+	}
+}
+
+// getSignedPreKeyCreatedAt returns the creation time of the current signed prekey. If the key isn't in the
+// maintenance store yet (e.g. it was created before rotation was implemented), it's stored with the current time.
+func (cli *Client) getSignedPreKeyCreatedAt() (time.Time, error) {
+	info, err := cli.Store.PreKeyHealth.GetSignedPreKey(cli.Store.SignedPreKey.KeyID)
+	if err != nil {
+		return time.Time{}, err
+	} else if info != nil {
+		return info.CreatedAt, nil
+	}
+	now := time.Now()
+	err = cli.Store.PreKeyHealth.PutSignedPreKey(cli.Store.SignedPreKey, now)
+	return now, err
+}
+
+// GetPreKeyHealth returns statistics about the prekeys of the client.
+func (cli *Client) GetPreKeyHealth() (*PreKeyHealth, error) {
+	if cli.Store.PreKeyHealth == nil {
+		return nil, fmt.Errorf("prekey maintenance store is not available")
+	}
+	serverCount, err := cli.getServerPreKeyCount()
+	if err != nil {
+		return nil, err
+	}
+	health := &PreKeyHealth{
+		ServerCount:    serverCount,
+		SignedPreKeyID: cli.Store.SignedPreKey.KeyID,
+	}
+	health.LocalUploaded, health.LocalUnuploaded, err = cli.Store.PreKeyHealth.GetPreKeyCounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local prekey counts: %w", err)
+	}
+	health.SignedPreKeyCreatedAt, err = cli.getSignedPreKeyCreatedAt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signed prekey creation time: %w", err)
+	}
+	if SignedPreKeyRotationInterval > 0 {
+		health.NextRotation = health.SignedPreKeyCreatedAt.Add(SignedPreKeyRotationInterval)
+	}
+	return health, nil
+}
+
+// RotateSignedPreKey generates a new signed prekey and uploads it to the server.
+//
+// The previous key is kept for SignedPreKeyGracePeriod, as other users may still start sessions using it.
+// The signed prekey is rotated automatically every SignedPreKeyRotationInterval, so this doesn't usually need to be called manually.
+func (cli *Client) RotateSignedPreKey(ctx context.Context) error {
+	if cli.Store.PreKeyHealth == nil {
+		return fmt.Errorf("prekey maintenance store is not available")
+	}
+	cli.uploadPreKeysLock.Lock()
+	defer cli.uploadPreKeysLock.Unlock()
+	oldKey := cli.Store.SignedPreKey
+	oldCreatedAt, err := cli.getSignedPreKeyCreatedAt()
+	if err != nil {
+		return fmt.Errorf("failed to store previous signed prekey: %w", err)
+	}
+	newKey := cli.Store.IdentityKey.CreateSignedPreKey((oldKey.KeyID + 1) & signedPreKeyIDMask)
+	now := time.Now()
+	// Store the new key before uploading it, so that it can be loaded if messages using it arrive before the device is saved
+	err = cli.Store.PreKeyHealth.PutSignedPreKey(newKey, now)
+	if err != nil {
+		return fmt.Errorf("failed to store new signed prekey: %w", err)
+	}
+	_, err = cli.sendIQ(infoQuery{
+		Context:   ctx,
+		Namespace: "encrypt",
+		Type:      iqSet,
+		To:        types.ServerJID,
+		Content: []waBinary.Node{{
+			Tag:     "rotate",
+			Content: []waBinary.Node{preKeyToNode(newKey)},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload new signed prekey: %w", err)
+	}
+	cli.Store.SignedPreKey = newKey
+	err = cli.Store.Save()
+	if err != nil {
+		return fmt.Errorf("failed to save new signed prekey: %w", err)
+	}
+	err = cli.Store.PreKeyHealth.RetireSignedPreKey(oldKey.KeyID, now)
+	if err != nil {
+		cli.Log.Warnf("Failed to mark previous signed prekey %d as retired: %v", oldKey.KeyID, err)
+	}
+	cli.Log.Infof("Rotated signed prekey %d (created at %s) to %d", oldKey.KeyID, oldCreatedAt, newKey.KeyID)
+	cli.dispatchEvent(&events.SignedPreKeyRotated{PreviousID: oldKey.KeyID, NewID: newKey.KeyID})
+	return nil
+}
+
+// PrunePreKeys deletes signed prekeys that were retired more than SignedPreKeyGracePeriod ago,
+// and uploaded one-time prekeys that are older than UploadedPreKeyMaxAge if it's set.
+// Prekeys uploaded before the upload time was tracked are never pruned.
+//
+// Pruning happens automatically while connected, so this doesn't usually need to be called manually.
+func (cli *Client) PrunePreKeys() (signedPreKeys, preKeys int64, err error) {
+	if cli.Store.PreKeyHealth == nil {
+		return 0, 0, fmt.Errorf("prekey maintenance store is not available")
+	}
+	signedPreKeys, err = cli.Store.PreKeyHealth.DeleteRetiredSignedPreKeys(time.Now().Add(-SignedPreKeyGracePeriod))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to delete retired signed prekeys: %w", err)
+	}
+	if UploadedPreKeyMaxAge > 0 {
+		preKeys, err = cli.Store.PreKeyHealth.DeleteUploadedPreKeys(time.Now().Add(-UploadedPreKeyMaxAge))
+		if err != nil {
+			return signedPreKeys, 0, fmt.Errorf("failed to delete old prekeys: %w", err)
+		}
+	}
+	if signedPreKeys > 0 || preKeys > 0 {
+		cli.Log.Debugf("Pruned %d retired signed prekeys and %d old one-time prekeys", signedPreKeys, preKeys)
+	}
+	return
+}
+
+func (cli *Client) maintainPreKeys(ctx context.Context) {
+	if SignedPreKeyRotationInterval > 0 {
+		createdAt, err := cli.getSignedPreKeyCreatedAt()
+		if err != nil {
+			cli.Log.Warnf("Failed to get signed prekey creation time: %v", err)
+		} else if time.Since(createdAt) > SignedPreKeyRotationInterval {
+			err = cli.RotateSignedPreKey(ctx)
+			if err != nil {
+				cli.Log.Errorf("Failed to rotate signed prekey: %v", err)
+			}
+		}
+	}
+	_, _, err := cli.PrunePreKeys()
+	if err != nil {
+		cli.Log.Warnf("Failed to prune prekeys: %v", err)
+	}
+}
+
+func (cli *Client) preKeyMaintenanceLoop(ctx context.Context) {
+	if cli.Store.PreKeyHealth == nil {
+		return
+	}
+	ticker := time.NewTicker(preKeyMaintenanceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if cli.IsLoggedIn() {
+				cli.maintainPreKeys(ctx)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
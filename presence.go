@@ -7,6 +7,7 @@
 package whatsmeow
 
 import (
+	"errors"
 	"fmt"
 
 	waBinary "github.com/sofyan48/whatsmeow/binary"
@@ -27,11 +28,13 @@ func (cli *Client) handleChatState(node *waBinary.Node) {
 			cli.Log.Warnf("Unrecognized chat presence state %s", child.Tag)
 		}
 		media := types.ChatPresenceMedia(child.AttrGetter().OptionalString("media"))
-		cli.dispatchEvent(&events.ChatPresence{
+		evt := &events.ChatPresence{
 			MessageSource: source,
 			State:         presence,
 			Media:         media,
-		})
+		}
+		cli.updateTypingCache(evt)
+		cli.dispatchEvent(evt)
 	}
 }
 
@@ -52,6 +55,7 @@ func (cli *Client) handlePresence(node *waBinary.Node) {
 	if !ag.OK() {
 		cli.Log.Warnf("Error parsing presence event: %+v", ag.Errors)
 	} else {
+		cli.updatePresenceCache(&evt)
 		cli.dispatchEvent(&evt)
 	}
 }
@@ -81,12 +85,47 @@ func (cli *Client) SendPresence(state types.Presence) error {
 // SubscribePresence asks the WhatsApp servers to send presence updates of a specific user to this client.
 //
 // After subscribing to this event, you should start receiving *events.Presence for that user in normal event handlers.
+// The latest presence is also available from GetPresence. Subscriptions are remembered and sent again automatically
+// after reconnecting, until UnsubscribePresence is called.
+//
+// If there's no privacy token stored for the user, the client will issue its own token to them, which usually makes
+// their client send a token back. The subscription is then sent again automatically when the token arrives, even if
+// this function returned ErrNoPrivacyToken (see ErrorOnSubscribePresenceWithoutToken).
 //
 // Also, it seems that the WhatsApp servers require you to be online to receive presence status from other users,
 // so you should mark yourself as online before trying to use this function:
 //
 //	cli.SendPresence(types.PresenceAvailable)
 func (cli *Client) SubscribePresence(jid types.JID) error {
+	// Re-subscriptions after reconnecting refresh tokens for all users at once, so this is only done here
+	// rather than in sendPresenceSubscription.
+	go cli.refreshPrivacyTokens([]types.JID{jid.ToNonAD()})
+	err := cli.sendPresenceSubscription(jid)
+	if err != nil && !errors.Is(err, ErrNoPrivacyToken) {
+		return err
+	}
+	cli.presenceSubscriptionsLock.Lock()
+	cli.presenceSubscriptions[jid.ToNonAD()] = struct{}{}
+	cli.presenceSubscriptionsLock.Unlock()
+	return err
+}
+
+// UnsubscribePresence asks the WhatsApp servers to stop sending presence updates of a specific user,
+// and removes the user from the list of subscriptions that are restored after reconnecting.
+func (cli *Client) UnsubscribePresence(jid types.JID) error {
+	cli.presenceSubscriptionsLock.Lock()
+	delete(cli.presenceSubscriptions, jid.ToNonAD())
+	cli.presenceSubscriptionsLock.Unlock()
+	return cli.sendNode(waBinary.Node{
+		Tag: "presence",
+		Attrs: waBinary.Attrs{
+			"type": "unsubscribe",
+			"to":   jid,
+		},
+	})
+}
+
+func (cli *Client) sendPresenceSubscription(jid types.JID) error {
 	privacyToken, err := cli.Store.PrivacyTokens.GetPrivacyToken(jid)
 	if err != nil {
 		return fmt.Errorf("failed to get privacy token: %w", err)
@@ -0,0 +1,171 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"time"
+
+	types "github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+// DefaultComposingTimeout is the default value for Client.ComposingTimeout.
+//
+// Official clients resend the composing state every few seconds while typing,
+// so a state that hasn't been refreshed for this long is most likely stale.
+const DefaultComposingTimeout = 30 * time.Second
+
+type typingState struct {
+	types.TypingUser
+	source types.MessageSource
+	timer  *time.Timer
+}
+
+func (cli *Client) updatePresenceCache(evt *events.Presence) {
+	cli.presenceCacheLock.Lock()
+	defer cli.presenceCacheLock.Unlock()
+	jid := evt.From.ToNonAD()
+	prev, ok := cli.presenceCache[jid]
+	presence := types.UserPresence{
+		JID:       jid,
+		Available: !evt.Unavailable,
+		LastSeen:  evt.LastSeen,
+		UpdatedAt: time.Now(),
+	}
+	if presence.LastSeen.IsZero() && evt.Unavailable && ok && prev.Available {
+		// The user just went offline, so they were last seen now even if the server didn't say so.
+		presence.LastSeen = presence.UpdatedAt
+	} else if presence.LastSeen.IsZero() && ok {
+		presence.LastSeen = prev.LastSeen
+	}
+	cli.presenceCache[jid] = presence
+}
+
+func (cli *Client) updateTypingCache(evt *events.ChatPresence) {
+	cli.presenceCacheLock.Lock()
+	defer cli.presenceCacheLock.Unlock()
+	chat := evt.Chat.ToNonAD()
+	user := evt.Sender.ToNonAD()
+	chatTyping := cli.typingCache[chat]
+	existing, ok := chatTyping[user]
+	if ok {
+		existing.timer.Stop()
+	}
+	if evt.State != types.ChatPresenceComposing {
+		if ok {
+			delete(chatTyping, user)
+			if len(chatTyping) == 0 {
+				delete(cli.typingCache, chat)
+			}
+		}
+		return
+	}
+	if chatTyping == nil {
+		chatTyping = make(map[types.JID]*typingState)
+		cli.typingCache[chat] = chatTyping
+	}
+	state := &typingState{
+		TypingUser: types.TypingUser{User: user, Media: evt.Media, Since: time.Now()},
+		source:     evt.MessageSource,
+	}
+	if ok && existing.Media == evt.Media {
+		state.Since = existing.Since
+	}
+	timeout := cli.ComposingTimeout
+	if timeout <= 0 {
+		timeout = DefaultComposingTimeout
+	}
+	state.timer = time.AfterFunc(timeout, func() {
+		cli.expireTypingState(chat, user, state)
+	})
+	chatTyping[user] = state
+}
+
+func (cli *Client) expireTypingState(chat, user types.JID, state *typingState) {
+	cli.presenceCacheLock.Lock()
+	if cli.typingCache[chat][user] != state {
+		cli.presenceCacheLock.Unlock()
+		return
+	}
+	delete(cli.typingCache[chat], user)
+	if len(cli.typingCache[chat]) == 0 {
+		delete(cli.typingCache, chat)
+	}
+	cli.presenceCacheLock.Unlock()
+	cli.Log.Debugf("Composing state of %s timed out", state.source.SourceString())
+	cli.dispatchEvent(&events.ChatPresence{
+		MessageSource: state.source,
+		State:         types.ChatPresencePaused,
+		Media:         state.Media,
+		TimedOut:      true,
+	})
+}
+
+// GetPresence returns the last known presence of the given user.
+//
+// Presence updates are only received for users you've subscribed to using SubscribePresence.
+// The second return value is false if no presence update has been received for the user.
+func (cli *Client) GetPresence(jid types.JID) (types.UserPresence, bool) {
+	cli.presenceCacheLock.Lock()
+	defer cli.presenceCacheLock.Unlock()
+	presence, ok := cli.presenceCache[jid.ToNonAD()]
+	return presence, ok
+}
+
+// GetTypingUsers returns the users who are currently typing or recording audio in the given chat.
+//
+// Composing states that aren't refreshed within ComposingTimeout are removed automatically.
+func (cli *Client) GetTypingUsers(chat types.JID) []types.TypingUser {
+	cli.presenceCacheLock.Lock()
+	defer cli.presenceCacheLock.Unlock()
+	chatTyping := cli.typingCache[chat.ToNonAD()]
+	users := make([]types.TypingUser, 0, len(chatTyping))
+	for _, state := range chatTyping {
+		users = append(users, state.TypingUser)
+	}
+	return users
+}
+
+func (cli *Client) resubscribePresences() {
+	cli.presenceSubscriptionsLock.Lock()
+	jids := make([]types.JID, 0, len(cli.presenceSubscriptions))
+	for jid := range cli.presenceSubscriptions {
+		jids = append(jids, jid)
+	}
+	cli.presenceSubscriptionsLock.Unlock()
+	if len(jids) == 0 {
+		return
+	}
+	cli.refreshPrivacyTokens(jids)
+	cli.Log.Debugf("Re-subscribing to presence of %d users after connecting", len(jids))
+	failed := make(map[types.JID]error)
+	for _, jid := range jids {
+		err := cli.sendPresenceSubscription(jid)
+		if err != nil {
+			cli.Log.Warnf("Failed to re-subscribe to presence of %s: %v", jid, err)
+			failed[jid] = err
+		}
+	}
+	if len(failed) > 0 {
+		cli.dispatchEvent(&events.PresenceSubscriptionFailed{Errors: failed})
+	}
+}
+
+func (cli *Client) resubscribeWithNewToken(jid types.JID) {
+	err := cli.sendPresenceSubscription(jid)
+	if err != nil {
+		cli.Log.Warnf("Failed to re-subscribe to presence of %s after receiving new privacy token: %v", jid, err)
+		cli.dispatchEvent(&events.PresenceSubscriptionFailed{Errors: map[types.JID]error{jid: err}})
+	}
+}
+
+func (cli *Client) isSubscribedToPresence(jid types.JID) bool {
+	cli.presenceSubscriptionsLock.Lock()
+	_, ok := cli.presenceSubscriptions[jid.ToNonAD()]
+	cli.presenceSubscriptionsLock.Unlock()
+	return ok
+}
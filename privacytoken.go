@@ -0,0 +1,92 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	waBinary "github.com/sofyan48/whatsmeow/binary"
+	types "github.com/sofyan48/whatsmeow/types"
+)
+
+// PrivacyTokenRefreshInterval is how old a stored privacy token can be before the client
+// issues a new token to the user when re-subscribing to their presence.
+const PrivacyTokenRefreshInterval = 7 * 24 * time.Hour
+
+// IssuePrivacyTokens sends our trusted contact privacy tokens to the given users.
+//
+// Official clients do this when starting a chat with someone, and the other user's client normally responds by
+// issuing its own token, which is received as a privacy_token notification and stored in Store.PrivacyTokens.
+// Those tokens are required to subscribe to the user's presence (see SubscribePresence).
+func (cli *Client) IssuePrivacyTokens(ctx context.Context, jids ...types.JID) error {
+	if len(jids) == 0 {
+		return nil
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	tokens := make([]waBinary.Node, len(jids))
+	for i, jid := range jids {
+		tokens[i] = waBinary.Node{
+			Tag: "token",
+			Attrs: waBinary.Attrs{
+				"jid":  jid.ToNonAD(),
+				"t":    ts,
+				"type": "trusted_contact",
+			},
+		}
+	}
+	_, err := cli.sendIQ(infoQuery{
+		Namespace: "privacy",
+		Type:      iqSet,
+		To:        types.ServerJID,
+		Context:   ctx,
+		Content: []waBinary.Node{{
+			Tag:     "tokens",
+			Content: tokens,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	cli.presenceSubscriptionsLock.Lock()
+	for _, jid := range jids {
+		cli.issuedPrivacyTokens[jid.ToNonAD()] = now
+	}
+	cli.presenceSubscriptionsLock.Unlock()
+	return nil
+}
+
+// refreshPrivacyTokens issues privacy tokens to the given users if there's no stored token from them or if the
+// stored token is older than PrivacyTokenRefreshInterval, unless tokens were already issued to them recently.
+func (cli *Client) refreshPrivacyTokens(jids []types.JID) {
+	var needTokens []types.JID
+	for _, jid := range jids {
+		token, err := cli.Store.PrivacyTokens.GetPrivacyToken(jid)
+		if err != nil {
+			cli.Log.Warnf("Failed to get privacy token of %s: %v", jid, err)
+			continue
+		} else if token != nil && time.Since(token.Timestamp) < PrivacyTokenRefreshInterval {
+			continue
+		}
+		cli.presenceSubscriptionsLock.Lock()
+		lastIssued := cli.issuedPrivacyTokens[jid]
+		cli.presenceSubscriptionsLock.Unlock()
+		if time.Since(lastIssued) >= PrivacyTokenRefreshInterval {
+			needTokens = append(needTokens, jid)
+		}
+	}
+	if len(needTokens) == 0 {
+		return
+	}
+	cli.Log.Debugf("Issuing privacy tokens to %d users with missing or outdated tokens", len(needTokens))
+	err := cli.IssuePrivacyTokens(context.TODO(), needTokens...)
+	if err != nil {
+		cli.Log.Warnf("Failed to issue privacy tokens: %v", err)
+	}
+}
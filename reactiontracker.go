@@ -0,0 +1,126 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+// trackReactions stores incoming and outgoing reactions and dispatches ReactionsChanged events.
+func (cli *Client) trackReactions(evt *events.Message) {
+	if cli.Store.Reactions == nil {
+		return
+	}
+	var reaction *waProto.ReactionMessage
+	var targetID types.MessageID
+	if reaction = evt.Message.GetReactionMessage(); reaction != nil {
+		targetID = types.MessageID(reaction.GetKey().GetId())
+	} else if encReaction := evt.Message.GetEncReactionMessage(); encReaction != nil {
+		var err error
+		reaction, err = cli.DecryptReaction(evt)
+		if err != nil {
+			cli.Log.Warnf("Failed to decrypt reaction %s from %s: %v", evt.Info.ID, evt.Info.SourceString(), err)
+			return
+		}
+		targetID = types.MessageID(encReaction.GetTargetMessageKey().GetId())
+	} else {
+		return
+	}
+	stored := types.Reaction{
+		Chat:      evt.Info.Chat,
+		MessageID: targetID,
+		Sender:    evt.Info.Sender.ToNonAD(),
+		Text:      reaction.GetText(),
+		Timestamp: evt.Info.Timestamp,
+	}
+	if reaction.SenderTimestampMs != nil {
+		stored.Timestamp = time.UnixMilli(reaction.GetSenderTimestampMs())
+	}
+	err := cli.Store.Reactions.PutReaction(stored)
+	if err != nil {
+		cli.Log.Warnf("Failed to store reaction from %s to %s: %v", evt.Info.SourceString(), targetID, err)
+		return
+	}
+	reactions, err := cli.GetReactions(evt.Info.Chat, targetID)
+	if err != nil {
+		cli.Log.Warnf("Failed to get reactions to %s after storing reaction: %v", targetID, err)
+		return
+	}
+	cli.dispatchEvent(&events.ReactionsChanged{
+		Reaction:  stored,
+		Reactions: reactions,
+	})
+}
+
+// GetReactions returns the current reactions to the given message from the device store.
+//
+// Reactions are tracked automatically when they're received or sent through this client.
+// Only the latest reaction of each user is kept, and removed reactions are not included.
+func (cli *Client) GetReactions(chat types.JID, messageID types.MessageID) (*types.MessageReactions, error) {
+	if cli.Store.Reactions == nil {
+		return nil, fmt.Errorf("reaction store is not available")
+	}
+	reactions, err := cli.Store.Reactions.GetReactions(chat, messageID)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.MessageReactions{
+		Chat:      chat.ToNonAD(),
+		MessageID: messageID,
+		Reactions: reactions,
+		Counts:    make(map[string]int),
+	}
+	for _, reaction := range reactions {
+		result.Counts[reaction.Text]++
+	}
+	return result, nil
+}
+
+// BuildEncryptedReaction builds an encrypted reaction message using the given target message info.
+// The built message can be sent normally using Client.SendMessage.
+//
+// Encrypted reactions are used in community announcement groups. The target message must have a message secret,
+// which means it must have been received or sent by this client. Use an empty string as the reaction to remove it.
+//
+//	reactionMsg, err := cli.BuildEncryptedReaction(&evt.Info, "🐈️")
+//	if err != nil {
+//		fmt.Println(":(", err)
+//		return
+//	}
+//	resp, err := cli.SendMessage(context.Background(), evt.Info.Chat, reactionMsg)
+func (cli *Client) BuildEncryptedReaction(target *types.MessageInfo, reaction string) (*waProto.Message, error) {
+	encReaction, err := cli.EncryptReaction(target, &waProto.ReactionMessage{
+		Key:               getKeyFromInfo(target),
+		Text:              proto.String(reaction),
+		SenderTimestampMs: proto.Int64(time.Now().UnixMilli()),
+	})
+	return &waProto.Message{EncReactionMessage: encReaction}, err
+}
+
+// EncryptReaction encrypts a reaction message. This is a slightly lower-level function, using BuildEncryptedReaction is recommended.
+func (cli *Client) EncryptReaction(target *types.MessageInfo, reaction *waProto.ReactionMessage) (*waProto.EncReactionMessage, error) {
+	plaintext, err := proto.Marshal(reaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal reaction protobuf: %w", err)
+	}
+	ciphertext, iv, err := cli.encryptMsgSecret(target.Chat, target.Sender, target.ID, EncSecretReaction, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt reaction: %w", err)
+	}
+	return &waProto.EncReactionMessage{
+		TargetMessageKey: getKeyFromInfo(target),
+		EncPayload:       ciphertext,
+		EncIv:            iv,
+	}, nil
+}
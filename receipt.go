@@ -29,6 +29,7 @@ func (cli *Client) handleReceipt(node *waBinary.Node) {
 			}()
 		}
 		go cli.dispatchEvent(receipt)
+		cli.trackReceiptStatus(receipt)
 	}
 	go cli.sendAck(node)
 }
@@ -50,6 +51,7 @@ func (cli *Client) handleGroupedReceipt(partialReceipt events.Receipt, participa
 			continue
 		}
 		go cli.dispatchEvent(&receipt)
+		cli.trackReceiptStatus(&receipt)
 	}
 }
 
@@ -0,0 +1,115 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ReconnectPolicy decides how long the client waits between automatic reconnection attempts.
+type ReconnectPolicy interface {
+	// NextDelay returns the delay before the given reconnection attempt. The attempt number starts from 1 and is
+	// reset after a successful connection. lastErr is the error from the previous attempt, or nil for the first one.
+	// If ok is false, the client stops trying to reconnect.
+	NextDelay(attempt int, lastErr error) (delay time.Duration, ok bool)
+}
+
+// ExponentialBackoff is a ReconnectPolicy that doubles (or multiplies by Multiplier) the delay after each failed
+// attempt up to MaxDelay, with optional random jitter and a simple circuit breaker.
+type ExponentialBackoff struct {
+	// The delay before the first reconnection attempt.
+	BaseDelay time.Duration
+	// The maximum delay between attempts (before jitter).
+	MaxDelay time.Duration
+	// The factor the delay is multiplied by after each failed attempt. Defaults to 2.
+	Multiplier float64
+	// The fraction of the delay that is randomized, between 0 and 1.
+	// For example, with a jitter of 0.5, a 10 second delay becomes a random delay between 5 and 10 seconds.
+	Jitter float64
+	// The maximum number of attempts. Zero means the client never gives up.
+	MaxAttempts int
+
+	// After this many consecutive failed attempts, the circuit breaker opens and the client only tries to reconnect
+	// once every BreakerCooldown (or MaxDelay if the cooldown isn't set). Zero disables the circuit breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+var _ ReconnectPolicy = (*ExponentialBackoff)(nil)
+
+// DefaultReconnectPolicy is the ReconnectPolicy used when Client.ReconnectPolicy is nil.
+var DefaultReconnectPolicy ReconnectPolicy = &ExponentialBackoff{
+	BaseDelay:  2 * time.Second,
+	MaxDelay:   5 * time.Minute,
+	Multiplier: 2,
+	Jitter:     0.5,
+}
+
+func (eb *ExponentialBackoff) NextDelay(attempt int, _ error) (time.Duration, bool) {
+	if eb.MaxAttempts > 0 && attempt > eb.MaxAttempts {
+		return 0, false
+	}
+	if eb.BreakerThreshold > 0 && attempt > eb.BreakerThreshold {
+		if eb.BreakerCooldown > 0 {
+			return eb.withJitter(eb.BreakerCooldown), true
+		} else if eb.MaxDelay > 0 {
+			return eb.withJitter(eb.MaxDelay), true
+		}
+	}
+	if eb.BaseDelay <= 0 {
+		return 0, true
+	}
+	multiplier := eb.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	delay := float64(eb.BaseDelay) * math.Pow(multiplier, float64(attempt-1))
+	if eb.MaxDelay > 0 && delay > float64(eb.MaxDelay) {
+		delay = float64(eb.MaxDelay)
+	} else if delay >= math.MaxInt64 {
+		// Without a MaxDelay, the delay would eventually overflow into negative values
+		return eb.withJitter(math.MaxInt64), true
+	}
+	return eb.withJitter(time.Duration(delay)), true
+}
+
+func (eb *ExponentialBackoff) withJitter(delay time.Duration) time.Duration {
+	if eb.Jitter <= 0 || delay <= 0 {
+		return delay
+	}
+	jitter := eb.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	return delay - time.Duration(rand.Float64()*jitter*float64(delay))
+}
+
+func (cli *Client) getReconnectPolicy() ReconnectPolicy {
+	if cli.ReconnectPolicy != nil {
+		return cli.ReconnectPolicy
+	}
+	return DefaultReconnectPolicy
+}
+
+// Reconnect skips the remaining delay if the client is currently waiting to automatically reconnect.
+// This is meant to be called when the network changes (e.g. the device switched networks or came back online).
+//
+// If the client isn't waiting to reconnect, the current connection is closed and a new one is opened immediately,
+// as the old connection may be bound to a network that is no longer available.
+func (cli *Client) Reconnect() error {
+	if cli.autoReconnecting.Load() {
+		select {
+		case cli.reconnectNudge <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+	cli.Disconnect()
+	return cli.Connect()
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffNextDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ExponentialBackoff
+		attempt int
+		delay   time.Duration
+		ok      bool
+	}{
+		{"First attempt", ExponentialBackoff{BaseDelay: time.Second}, 1, time.Second, true},
+		{"Default multiplier", ExponentialBackoff{BaseDelay: time.Second}, 4, 8 * time.Second, true},
+		{"Custom multiplier", ExponentialBackoff{BaseDelay: time.Second, Multiplier: 3}, 3, 9 * time.Second, true},
+		{"Capped at max delay", ExponentialBackoff{BaseDelay: time.Second, MaxDelay: time.Minute}, 10, time.Minute, true},
+		{"No overflow without max delay", ExponentialBackoff{BaseDelay: time.Second}, 100, math.MaxInt64, true},
+		{"No overflow at huge attempt", ExponentialBackoff{BaseDelay: time.Second}, math.MaxInt32, math.MaxInt64, true},
+		{"No base delay", ExponentialBackoff{MaxDelay: time.Minute}, math.MaxInt32, 0, true},
+		{"Within max attempts", ExponentialBackoff{BaseDelay: time.Second, MaxAttempts: 3}, 3, 4 * time.Second, true},
+		{"Past max attempts", ExponentialBackoff{BaseDelay: time.Second, MaxAttempts: 3}, 4, 0, false},
+		{"Breaker closed", ExponentialBackoff{BaseDelay: time.Second, BreakerThreshold: 3, BreakerCooldown: time.Hour}, 3, 4 * time.Second, true},
+		{"Breaker open", ExponentialBackoff{BaseDelay: time.Second, BreakerThreshold: 3, BreakerCooldown: time.Hour}, 4, time.Hour, true},
+		{"Breaker without cooldown uses max delay", ExponentialBackoff{BaseDelay: time.Second, MaxDelay: time.Minute, BreakerThreshold: 3}, 4, time.Minute, true},
+		{"Breaker without cooldown or max delay", ExponentialBackoff{BaseDelay: time.Second, BreakerThreshold: 3}, 4, 8 * time.Second, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			delay, ok := test.policy.NextDelay(test.attempt, nil)
+			if delay != test.delay || ok != test.ok {
+				t.Errorf("expected (%s, %t), got (%s, %t)", test.delay, test.ok, delay, ok)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoffJitter(t *testing.T) {
+	policy := ExponentialBackoff{BaseDelay: 10 * time.Second, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		delay, _ := policy.NextDelay(1, nil)
+		if delay < 5*time.Second || delay > 10*time.Second {
+			t.Fatalf("jittered delay %s out of range", delay)
+		}
+	}
+	policy = ExponentialBackoff{BaseDelay: time.Second, Jitter: 1}
+	for i := 0; i < 100; i++ {
+		delay, _ := policy.NextDelay(200, nil)
+		if delay < 0 {
+			t.Fatalf("jittered delay %s is negative", delay)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"fmt"
+	"strings"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+	"github.com/sofyan48/whatsmeow/types"
+	"github.com/sofyan48/whatsmeow/types/events"
+)
+
+// getSearchableText returns the type and indexable text of the given message,
+// or empty strings if the message doesn't contain any searchable text.
+func getSearchableText(msg *waProto.Message) (msgType, text string) {
+	switch {
+	case msg.Conversation != nil:
+		return types.SearchMessageTypeText, msg.GetConversation()
+	case msg.ExtendedTextMessage != nil:
+		return types.SearchMessageTypeText, msg.GetExtendedTextMessage().GetText()
+	case msg.ImageMessage != nil:
+		return types.SearchMessageTypeImage, msg.GetImageMessage().GetCaption()
+	case msg.VideoMessage != nil:
+		return types.SearchMessageTypeVideo, msg.GetVideoMessage().GetCaption()
+	case msg.DocumentMessage != nil:
+		doc := msg.GetDocumentMessage()
+		return types.SearchMessageTypeDocument, strings.TrimSpace(doc.GetFileName() + " " + doc.GetCaption())
+	default:
+		return "", ""
+	}
+}
+
+func getSearchEntry(evt *events.Message) (entry types.MessageSearchEntry, ok bool) {
+	entry = types.MessageSearchEntry{
+		Chat:      evt.Info.Chat,
+		Sender:    evt.Info.Sender,
+		ID:        evt.Info.ID,
+		Timestamp: evt.Info.Timestamp,
+	}
+	entry.Type, entry.Text = getSearchableText(evt.Message)
+	return entry, len(strings.TrimSpace(entry.Text)) > 0
+}
+
+func (cli *Client) indexMessage(evt *events.Message) {
+	if cli.Store.MessageSearch == nil {
+		return
+	}
+	protoMsg := evt.Message.GetProtocolMessage()
+	var err error
+	switch {
+	case evt.IsEdit && protoMsg.GetType() == waProto.ProtocolMessage_MESSAGE_EDIT:
+		// Edits replace the indexed text of the original message
+		entry := types.MessageSearchEntry{
+			Chat:      evt.Info.Chat,
+			Sender:    evt.Info.Sender,
+			ID:        types.MessageID(protoMsg.GetKey().GetId()),
+			Timestamp: evt.Info.Timestamp,
+		}
+		entry.Type, entry.Text = getSearchableText(protoMsg.GetEditedMessage())
+		if entry.Text != "" {
+			err = cli.Store.MessageSearch.IndexMessages(entry)
+		}
+	case protoMsg.GetType() == waProto.ProtocolMessage_REVOKE:
+		err = cli.Store.MessageSearch.DeleteIndexedMessage(evt.Info.Chat, types.MessageID(protoMsg.GetKey().GetId()))
+	default:
+		if entry, ok := getSearchEntry(evt); ok {
+			err = cli.Store.MessageSearch.IndexMessages(entry)
+		}
+	}
+	if err != nil {
+		cli.Log.Warnf("Failed to update search index for %s from %s: %v", evt.Info.ID, evt.Info.SourceString(), err)
+	}
+}
+
+func (cli *Client) indexHistoricalMessages(conversations []*waProto.Conversation) {
+	if cli.Store.MessageSearch == nil {
+		return
+	}
+	var entries []types.MessageSearchEntry
+	for _, conv := range conversations {
+		chatJID, _ := types.ParseJID(conv.GetId())
+		if chatJID.IsEmpty() {
+			continue
+		}
+		for _, msg := range conv.GetMessages() {
+			evt, err := cli.ParseWebMessage(chatJID, msg.GetMessage())
+			if err != nil || evt.Message == nil {
+				continue
+			}
+			if entry, ok := getSearchEntry(evt); ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+	err := cli.Store.MessageSearch.IndexMessages(entries...)
+	if err != nil {
+		cli.Log.Warnf("Failed to index %d messages from history sync: %v", len(entries), err)
+	} else {
+		cli.Log.Debugf("Indexed %d messages from history sync", len(entries))
+	}
+}
+
+// SearchMessages searches the local full-text message index.
+//
+// The index must be enabled in the store first (e.g. with sqlstore.Container.EnableMessageSearch).
+// Messages are indexed automatically as they're received or sent through this client and from history syncs.
+// Edited messages are re-indexed with the new text and revoked messages are removed from the index.
+//
+//	results, err := cli.SearchMessages(types.MessageSearchQuery{Query: "meow", Chat: chatJID, Limit: 20})
+func (cli *Client) SearchMessages(query types.MessageSearchQuery) ([]types.MessageSearchEntry, error) {
+	if cli.Store.MessageSearch == nil {
+		return nil, fmt.Errorf("message search index is not enabled")
+	}
+	return cli.Store.MessageSearch.SearchMessages(query)
+}
@@ -159,6 +159,8 @@ type SendRequestExtra struct {
 //
 // For uploading and sending media/attachments, see the Upload method.
 //
+// The msgbuilder package contains builders for common message types like replies, media, locations and contacts.
+//
 // For other message types, you'll have to figure it out yourself. Looking at the protobuf schema
 // in binary/proto/def.proto may be useful to find out all the allowed fields. Printing the RawMessage
 // field in incoming message events to figure out what it contains is also a good way to learn how to
@@ -180,6 +182,12 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waPro
 		err = ErrNotLoggedIn
 		return
 	}
+	if cli.BlockSendToChangedIdentity && !req.Peer && to.Server == types.DefaultUserServer {
+		err = cli.checkVerifiedIdentity(ctx, to)
+		if err != nil {
+			return
+		}
+	}
 
 	if req.Timeout == 0 {
 		req.Timeout = defaultRequestTimeout
@@ -196,6 +204,19 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waPro
 		}
 	}
 	resp.ID = req.ID
+	if !req.Peer {
+		message = cli.applyDisappearingTimer(to, message)
+	}
+
+	var sent *events.Message
+	// This is deferred before the unlock so that it runs after the send lock is released,
+	// as tracking may make network requests and the events it dispatches may send messages.
+	defer func() {
+		if sent != nil {
+			cli.trackMessage(sent.UnwrapRaw())
+			cli.trackSentMessage(sent)
+		}
+	}()
 
 	start := time.Now()
 	// Sending multiple messages at a time can cause weird issues and makes it harder to retry safely
@@ -269,6 +290,15 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waPro
 	resp.Timestamp = ag.UnixTime("t")
 	if errorCode := ag.Int("error"); errorCode != 0 {
 		err = fmt.Errorf("%w %d", ErrServerReturnedError, errorCode)
+	} else if !req.Peer {
+		sent = &events.Message{
+			Info: types.MessageInfo{
+				MessageSource: types.MessageSource{Chat: to, Sender: ownID, IsFromMe: true, IsGroup: to.Server != types.DefaultUserServer},
+				ID:            req.ID,
+				Timestamp:     resp.Timestamp,
+			},
+			RawMessage: message,
+		}
 	}
 	expectedPHash := ag.OptionalString("phash")
 	if len(expectedPHash) > 0 && phash != expectedPHash {
@@ -370,6 +400,8 @@ func (cli *Client) BuildUnavailableMessageRequest(chat, sender types.JID, id str
 //
 // The response will contain to `count` messages immediately before the given message.
 // The recommended number of messages to request at a time is 50.
+//
+// Client.RequestHistory can be used to send the request and wait for the response in one call.
 func (cli *Client) BuildHistorySyncRequest(lastKnownMessageInfo *types.MessageInfo, count int) *waProto.Message {
 	return &waProto.Message{
 		ProtocolMessage: &waProto.ProtocolMessage{
@@ -456,6 +488,9 @@ func (cli *Client) SetDisappearingTimer(chat types.JID, timer time.Duration) (er
 				EphemeralExpiration: proto.Uint32(uint32(timer.Seconds())),
 			},
 		})
+		if err == nil {
+			cli.storeDisappearingTimer(chat, timer, time.Now())
+		}
 	case types.GroupServer:
 		if timer == 0 {
 			_, err = cli.sendGroupIQ(context.TODO(), iqSet, chat, waBinary.Node{Tag: "not_ephemeral"})
@@ -654,7 +689,7 @@ func getTypeFromMessage(msg *waProto.Message) string {
 		return getTypeFromMessage(msg.DocumentWithCaptionMessage.Message)
 	case msg.ReactionMessage != nil:
 		return "reaction"
-	case msg.PollCreationMessage != nil, msg.PollUpdateMessage != nil:
+	case msg.PollCreationMessage != nil, msg.PollCreationMessageV2 != nil, msg.PollCreationMessageV3 != nil, msg.PollUpdateMessage != nil:
 		return "poll"
 	case getMediaTypeFromMessage(msg) != "":
 		return "media"
@@ -1005,7 +1040,9 @@ func (cli *Client) encryptMessageForDevice(plaintext []byte, to types.JID, bundl
 	if bundle != nil {
 		cli.Log.Debugf("Processing prekey bundle for %s", to)
 		err := builder.ProcessBundle(bundle)
-		if cli.AutoTrustIdentity && errors.Is(err, signalerror.ErrUntrustedIdentity) {
+		if errors.Is(err, signalerror.ErrUntrustedIdentity) && cli.shouldBlockIdentityChange(to) {
+			return nil, false, fmt.Errorf("%w: %v", ErrIdentityChanged, err)
+		} else if cli.AutoTrustIdentity && errors.Is(err, signalerror.ErrUntrustedIdentity) {
 			cli.Log.Warnf("Got %v error while trying to process prekey bundle for %s, clearing stored identity and retrying", err, to)
 			cli.clearUntrustedIdentity(to)
 			err = builder.ProcessBundle(bundle)
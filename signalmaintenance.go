@@ -0,0 +1,223 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sofyan48/whatsmeow/types"
+)
+
+// SignalMaintenanceOptions contains the options for Client.RunSignalMaintenance.
+type SignalMaintenanceOptions struct {
+	// If true, nothing is deleted, only the report of what would be deleted is returned.
+	DryRun bool
+	// Should sessions with devices that are no longer in their user's device list be removed?
+	PruneDeletedDevices bool
+	// Should sender keys of groups that the user is no longer a participant of be removed?
+	PruneLeftGroups bool
+	// If non-zero, sessions and identities that haven't been used in this long are removed.
+	//
+	// Removing an identity also removes the trust-on-first-use state for it: if the contact's identity changes
+	// before the next message, the new identity will be accepted silently without an IdentityChange event.
+	// Identities of verified contacts (see MarkIdentityTrusted) are never removed.
+	MaxIdleAge time.Duration
+}
+
+// SignalMaintenanceReport contains the Signal data that was removed by Client.RunSignalMaintenance
+// (or would've been removed in dry-run mode).
+type SignalMaintenanceReport struct {
+	DryRun bool
+
+	// Signal addresses of sessions with devices that no longer exist.
+	DeletedDeviceSessions []string
+	// Groups whose sender keys were removed because the user is no longer a participant.
+	LeftGroupSenderKeys []types.JID
+	// Signal addresses of sessions that haven't been used in MaxIdleAge.
+	StaleSessions []string
+	// Signal addresses of identities that haven't been used in MaxIdleAge.
+	StaleIdentities []string
+}
+
+// parseSignalAddress parses a Signal address string (user:device) back into a JID.
+// Addresses with an agent suffix aren't supported and return false.
+func parseSignalAddress(address string) (types.JID, bool) {
+	user, deviceStr, ok := strings.Cut(address, ":")
+	if !ok || strings.ContainsRune(user, '_') {
+		return types.EmptyJID, false
+	}
+	device, err := strconv.ParseUint(deviceStr, 10, 16)
+	if err != nil {
+		return types.EmptyJID, false
+	}
+	return types.NewADJID(user, 0, byte(device)), true
+}
+
+func (cli *Client) findDeletedDeviceSessions(ctx context.Context) ([]string, error) {
+	addresses, err := cli.Store.SignalCleanup.GetSessionAddresses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session list: %w", err)
+	}
+	sessionsByUser := make(map[types.JID][]types.JID)
+	addressByDevice := make(map[types.JID]string, len(addresses))
+	for _, address := range addresses {
+		jid, ok := parseSignalAddress(address)
+		if !ok {
+			continue
+		}
+		user := jid.ToNonAD()
+		sessionsByUser[user] = append(sessionsByUser[user], jid)
+		addressByDevice[jid] = address
+	}
+	users := make([]types.JID, 0, len(sessionsByUser))
+	for user := range sessionsByUser {
+		users = append(users, user)
+	}
+	existingDevices := make(map[types.JID]struct{})
+	usersWithDevices := make(map[types.JID]struct{})
+	for i := 0; i < len(users); i += signalMaintenanceBatchSize {
+		end := i + signalMaintenanceBatchSize
+		if end > len(users) {
+			end = len(users)
+		}
+		devices, err := cli.GetUserDevicesContext(ctx, users[i:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get device lists: %w", err)
+		}
+		for _, device := range devices {
+			existingDevices[device] = struct{}{}
+			usersWithDevices[device.ToNonAD()] = struct{}{}
+		}
+	}
+	var deleted []string
+	for user, sessions := range sessionsByUser {
+		if _, ok := usersWithDevices[user]; !ok {
+			// Don't touch users whose device list couldn't be fetched
+			continue
+		}
+		for _, device := range sessions {
+			if _, exists := existingDevices[device]; !exists {
+				deleted = append(deleted, addressByDevice[device])
+			}
+		}
+	}
+	return deleted, nil
+}
+
+func (cli *Client) findLeftGroupSenderKeys() ([]types.JID, error) {
+	chats, err := cli.Store.SignalCleanup.GetSenderKeyChats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sender key chat list: %w", err)
+	}
+	groups, err := cli.GetJoinedGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get joined groups: %w", err)
+	}
+	joined := make(map[types.JID]struct{}, len(groups))
+	for _, group := range groups {
+		joined[group.JID] = struct{}{}
+	}
+	var left []types.JID
+	for _, chat := range chats {
+		jid, err := types.ParseJID(chat)
+		// Sender keys are also used for status broadcasts, only groups can be checked against the joined group list
+		if err != nil || jid.Server != types.GroupServer {
+			continue
+		} else if _, ok := joined[jid]; !ok {
+			left = append(left, jid)
+		}
+	}
+	return left, nil
+}
+
+// signalMaintenanceBatchSize is the number of users whose device lists are fetched in one request.
+const signalMaintenanceBatchSize = 100
+
+// findStaleIdentities returns the addresses of identities that haven't been used since the given time,
+// excluding the primary device identities of verified contacts.
+func (cli *Client) findStaleIdentities(cutoff time.Time) ([]string, error) {
+	addresses, err := cli.Store.SignalCleanup.GetStaleIdentities(cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale identities: %w", err)
+	}
+	stale := addresses[:0]
+	for _, address := range addresses {
+		jid, ok := parseSignalAddress(address)
+		if ok && jid.Device == 0 && cli.isIdentityVerified(jid) {
+			continue
+		}
+		stale = append(stale, address)
+	}
+	return stale, nil
+}
+
+// RunSignalMaintenance finds and removes Signal sessions, identities and sender keys that are no longer needed.
+//
+// Sessions are removed if the device was removed from the user's device list, or if the session hasn't been used in
+// MaxIdleAge. Identities are removed if they haven't been used in MaxIdleAge and aren't verified. Sender keys are
+// removed for groups that the user isn't in anymore. If a removed session is needed later, a new one will be
+// established automatically.
+//
+// If opts.DryRun is true, nothing is removed, and the report contains what would have been removed.
+func (cli *Client) RunSignalMaintenance(ctx context.Context, opts SignalMaintenanceOptions) (*SignalMaintenanceReport, error) {
+	if cli.Store.SignalCleanup == nil {
+		return nil, fmt.Errorf("signal maintenance store is not available")
+	}
+	report := &SignalMaintenanceReport{DryRun: opts.DryRun}
+	var err error
+	if opts.PruneDeletedDevices {
+		report.DeletedDeviceSessions, err = cli.findDeletedDeviceSessions(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.PruneLeftGroups {
+		report.LeftGroupSenderKeys, err = cli.findLeftGroupSenderKeys()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.MaxIdleAge > 0 {
+		cutoff := time.Now().Add(-opts.MaxIdleAge)
+		report.StaleSessions, err = cli.Store.SignalCleanup.GetStaleSessions(cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stale sessions: %w", err)
+		}
+		report.StaleIdentities, err = cli.findStaleIdentities(cutoff)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+	for _, address := range append(report.DeletedDeviceSessions, report.StaleSessions...) {
+		err = cli.Store.Sessions.DeleteSession(address)
+		if err != nil {
+			return report, fmt.Errorf("failed to delete session with %s: %w", address, err)
+		}
+	}
+	for _, address := range report.StaleIdentities {
+		err = cli.Store.Identities.DeleteIdentity(address)
+		if err != nil {
+			return report, fmt.Errorf("failed to delete identity of %s: %w", address, err)
+		}
+	}
+	for _, group := range report.LeftGroupSenderKeys {
+		err = cli.Store.SignalCleanup.DeleteSenderKeysInChat(group.String())
+		if err != nil {
+			return report, fmt.Errorf("failed to delete sender keys in %s: %w", group, err)
+		}
+	}
+	cli.Log.Infof("Signal maintenance removed %d sessions of deleted devices, %d stale sessions, %d stale identities and sender keys of %d left groups",
+		len(report.DeletedDeviceSessions), len(report.StaleSessions), len(report.StaleIdentities), len(report.LeftGroupSenderKeys))
+	return report, nil
+}
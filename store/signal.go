@@ -142,6 +142,18 @@ func (device *Device) LoadSignedPreKey(signedPreKeyID uint32) *record.SignedPreK
 			ecc.NewDjbECPublicKey(*device.SignedPreKey.Pub),
 			ecc.NewDjbECPrivateKey(*device.SignedPreKey.Priv),
 		), *device.SignedPreKey.Signature, nil)
+	} else if device.PreKeyHealth != nil {
+		// Previous signed prekeys are kept for a while after rotation,
+		// as other users may have fetched them before the rotation.
+		info, err := device.PreKeyHealth.GetSignedPreKey(signedPreKeyID)
+		if err != nil {
+			device.Log.Errorf("Failed to load signed prekey %d: %v", signedPreKeyID, err)
+		} else if info != nil {
+			return record.NewSignedPreKey(signedPreKeyID, 0, ecc.NewECKeyPair(
+				ecc.NewDjbECPublicKey(*info.Key.Pub),
+				ecc.NewDjbECPrivateKey(*info.Key.Priv),
+			), *info.Key.Signature, nil)
+		}
 	}
 	return nil
 }
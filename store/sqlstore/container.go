@@ -28,6 +28,8 @@ type Container struct {
 	dialect string
 	log     waLog.Logger
 
+	messageSearch bool
+
 	DatabaseErrorHandler func(device *store.Device, action string, attemptIndex int, err error) (retry bool)
 }
 
@@ -136,6 +138,19 @@ func (c *Container) scanDevice(row scannable) (*store.Device, error) {
 	device.ChatSettings = innerStore
 	device.MsgSecrets = innerStore
 	device.PrivacyTokens = innerStore
+	device.BroadcastLists = innerStore
+	device.Polls = innerStore
+	device.Reactions = innerStore
+	device.EditHistory = innerStore
+	device.MessageStatus = innerStore
+	device.Disappearing = innerStore
+	device.HistorySyncs = innerStore
+	device.Verifications = innerStore
+	device.PreKeyHealth = innerStore
+	device.SignalCleanup = innerStore
+	if c.messageSearch {
+		device.MessageSearch = innerStore
+	}
 	device.Container = c
 	device.Initialized = true
 
@@ -194,6 +209,9 @@ const (
 				platform, business_name, push_name, facebook_uuid)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
+		signed_pre_key = VALUES(signed_pre_key),
+		signed_pre_key_id = VALUES(signed_pre_key_id),
+		signed_pre_key_sig = VALUES(signed_pre_key_sig),
 		platform = VALUES(platform),
 		business_name = VALUES(business_name),
 		push_name = VALUES(push_name)`
@@ -256,6 +274,19 @@ func (c *Container) PutDevice(device *store.Device) error {
 		device.ChatSettings = innerStore
 		device.MsgSecrets = innerStore
 		device.PrivacyTokens = innerStore
+		device.BroadcastLists = innerStore
+		device.Polls = innerStore
+		device.Reactions = innerStore
+		device.EditHistory = innerStore
+		device.MessageStatus = innerStore
+		device.Disappearing = innerStore
+		device.HistorySyncs = innerStore
+		device.Verifications = innerStore
+		device.PreKeyHealth = innerStore
+		device.SignalCleanup = innerStore
+		if c.messageSearch {
+			device.MessageSearch = innerStore
+		}
 		device.Initialized = true
 	}
 	return err
@@ -0,0 +1,153 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sqlstore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	store "github.com/sofyan48/whatsmeow/store"
+	types "github.com/sofyan48/whatsmeow/types"
+)
+
+// ErrEmptySearchQuery is returned by SearchMessages if the query doesn't contain any words.
+var ErrEmptySearchQuery = errors.New("search query must contain at least one word")
+
+const defaultSearchLimit = 50
+
+var _ store.MessageSearchStore = (*SQLStore)(nil)
+
+const (
+	createSearchTableQuery = `CREATE TABLE IF NOT EXISTS whatsmeow_message_search (
+		our_jid      VARCHAR(255),
+		chat_jid     VARCHAR(100),
+		message_id   VARCHAR(64),
+		sender_jid   VARCHAR(100) NOT NULL,
+		message_type VARCHAR(32)  NOT NULL,
+		timestamp    BIGINT NOT NULL,
+		text         TEXT   NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid, message_id),
+		FULLTEXT INDEX whatsmeow_message_search_text_idx (text),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`
+	indexMessageQuery = `
+		INSERT INTO whatsmeow_message_search (our_jid, chat_jid, message_id, sender_jid, message_type, timestamp, text)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE text = VALUES(text), message_type = VALUES(message_type)
+	`
+	deleteIndexedMessageQuery = `DELETE FROM whatsmeow_message_search WHERE our_jid=? AND chat_jid=? AND message_id=?`
+)
+
+// EnableMessageSearch creates the full-text search index table if it doesn't exist yet,
+// and makes devices loaded from this container have the MessageSearch store set.
+//
+// The index uses a MySQL FULLTEXT index, so words shorter than innodb_ft_min_token_size (3 by default)
+// can't be searched. This must be called before loading devices.
+func (c *Container) EnableMessageSearch() error {
+	_, err := c.db.Exec(createSearchTableQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create message search table: %w", err)
+	}
+	c.messageSearch = true
+	return nil
+}
+
+func (s *SQLStore) IndexMessages(entries ...types.MessageSearchEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for _, entry := range entries {
+		_, err = tx.Exec(indexMessageQuery, s.JID, entry.Chat.ToNonAD().String(), entry.ID, entry.Sender.ToNonAD().String(), entry.Type, entry.Timestamp.Unix(), entry.Text)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to insert index entry: %w", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLStore) DeleteIndexedMessage(chat types.JID, id types.MessageID) error {
+	_, err := s.db.Exec(deleteIndexedMessageQuery, s.JID, chat.ToNonAD().String(), id)
+	return err
+}
+
+// escapeFulltextQuery turns the query into a boolean mode FULLTEXT query that requires every word,
+// so that MySQL doesn't interpret any special syntax in user input.
+func escapeFulltextQuery(query string) string {
+	words := strings.Fields(strings.ReplaceAll(query, `"`, " "))
+	for i, word := range words {
+		words[i] = `+"` + word + `"`
+	}
+	return strings.Join(words, " ")
+}
+
+func (s *SQLStore) SearchMessages(query types.MessageSearchQuery) ([]types.MessageSearchEntry, error) {
+	ftQuery := escapeFulltextQuery(query.Query)
+	if ftQuery == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	args := []any{s.JID, ftQuery}
+	addArg := func(val any) string {
+		args = append(args, val)
+		return "?"
+	}
+	conditions := []string{"our_jid=?", "MATCH (text) AGAINST (? IN BOOLEAN MODE)"}
+	if !query.Chat.IsEmpty() {
+		conditions = append(conditions, "chat_jid="+addArg(query.Chat.ToNonAD().String()))
+	}
+	if !query.Sender.IsEmpty() {
+		conditions = append(conditions, "sender_jid="+addArg(query.Sender.ToNonAD().String()))
+	}
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "timestamp>="+addArg(query.Since.Unix()))
+	}
+	if !query.Until.IsZero() {
+		conditions = append(conditions, "timestamp<="+addArg(query.Until.Unix()))
+	}
+	if len(query.Types) > 0 {
+		placeholders := make([]string, len(query.Types))
+		for i, msgType := range query.Types {
+			placeholders[i] = addArg(msgType)
+		}
+		conditions = append(conditions, "message_type IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	sqlQuery := fmt.Sprintf(
+		"SELECT chat_jid, sender_jid, message_id, message_type, timestamp, text FROM whatsmeow_message_search WHERE %s ORDER BY timestamp DESC LIMIT %s",
+		strings.Join(conditions, " AND "), addArg(limit),
+	)
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var results []types.MessageSearchEntry
+	for rows.Next() {
+		var entry types.MessageSearchEntry
+		var ts int64
+		err = rows.Scan(&entry.Chat, &entry.Sender, &entry.ID, &entry.Type, &ts, &entry.Text)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		entry.Timestamp = time.Unix(ts, 0)
+		results = append(results, entry)
+	}
+	return results, rows.Err()
+}
@@ -10,12 +10,16 @@ package sqlstore
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
 	store "github.com/sofyan48/whatsmeow/store"
 	types "github.com/sofyan48/whatsmeow/types"
 	"github.com/sofyan48/whatsmeow/util/keys"
@@ -58,25 +62,37 @@ func NewSQLStore(c *Container, jid types.JID) *SQLStore {
 }
 
 var _ store.IdentityStore = (*SQLStore)(nil)
+var _ store.IdentityGetter = (*SQLStore)(nil)
 var _ store.SessionStore = (*SQLStore)(nil)
 var _ store.PreKeyStore = (*SQLStore)(nil)
 var _ store.SenderKeyStore = (*SQLStore)(nil)
 var _ store.AppStateSyncKeyStore = (*SQLStore)(nil)
 var _ store.AppStateStore = (*SQLStore)(nil)
 var _ store.ContactStore = (*SQLStore)(nil)
+var _ store.BroadcastListStore = (*SQLStore)(nil)
+var _ store.PollStore = (*SQLStore)(nil)
+var _ store.ReactionStore = (*SQLStore)(nil)
+var _ store.EditHistoryStore = (*SQLStore)(nil)
+var _ store.MessageStatusStore = (*SQLStore)(nil)
+var _ store.DisappearingTimerStore = (*SQLStore)(nil)
+var _ store.HistorySyncFailureStore = (*SQLStore)(nil)
+var _ store.IdentityVerificationStore = (*SQLStore)(nil)
+var _ store.PreKeyMaintenanceStore = (*SQLStore)(nil)
+var _ store.SignalMaintenanceStore = (*SQLStore)(nil)
 
 const (
-	putIdentityQuery = `INSERT INTO whatsmeow_identity_keys (our_jid, their_id, identity)
-	VALUES (?, ?, ?)
+	putIdentityQuery = `INSERT INTO whatsmeow_identity_keys (our_jid, their_id, identity, updated_at)
+	VALUES (?, ?, ?, ?)
 	ON DUPLICATE KEY UPDATE
-		identity = VALUES(identity)`
+		identity = VALUES(identity),
+		updated_at = VALUES(updated_at)`
 	deleteAllIdentitiesQuery = `DELETE FROM whatsmeow_identity_keys WHERE our_jid=? AND their_id LIKE ?`
 	deleteIdentityQuery      = `DELETE FROM whatsmeow_identity_keys WHERE our_jid=? AND their_id=?`
 	getIdentityQuery         = `SELECT identity FROM whatsmeow_identity_keys WHERE our_jid=? AND their_id=?`
 )
 
 func (s *SQLStore) PutIdentity(address string, key [32]byte) error {
-	_, err := s.db.Exec(putIdentityQuery, s.JID, address, key[:])
+	_, err := s.db.Exec(putIdentityQuery, s.JID, address, key[:], time.Now().Unix())
 	return err
 }
 
@@ -104,13 +120,27 @@ func (s *SQLStore) IsTrustedIdentity(address string, key [32]byte) (bool, error)
 	return *(*[32]byte)(existingIdentity) == key, nil
 }
 
+func (s *SQLStore) GetIdentity(address string) (key [32]byte, found bool, err error) {
+	var existingIdentity []byte
+	err = s.db.QueryRow(getIdentityQuery, s.JID, address).Scan(&existingIdentity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return key, false, nil
+	} else if err != nil {
+		return key, false, err
+	} else if len(existingIdentity) != 32 {
+		return key, false, ErrInvalidLength
+	}
+	return *(*[32]byte)(existingIdentity), true, nil
+}
+
 const (
 	getSessionQuery = `SELECT session FROM whatsmeow_sessions WHERE our_jid=? AND their_id=?`
 	hasSessionQuery = `SELECT true FROM whatsmeow_sessions WHERE our_jid=? AND their_id=?`
-	putSessionQuery = `INSERT INTO whatsmeow_sessions (our_jid, their_id, session)
-	VALUES (?, ?, ?)
+	putSessionQuery = `INSERT INTO whatsmeow_sessions (our_jid, their_id, session, updated_at)
+	VALUES (?, ?, ?, ?)
 	ON DUPLICATE KEY UPDATE
-		session = VALUES(session)`
+		session = VALUES(session),
+		updated_at = VALUES(updated_at)`
 	deleteAllSessionsQuery = `DELETE FROM whatsmeow_sessions WHERE our_jid=? AND their_id LIKE ?`
 	deleteSessionQuery     = `DELETE FROM whatsmeow_sessions WHERE our_jid=? AND their_id=?`
 )
@@ -132,7 +162,7 @@ func (s *SQLStore) HasSession(address string) (has bool, err error) {
 }
 
 func (s *SQLStore) PutSession(address string, session []byte) error {
-	_, err := s.db.Exec(putSessionQuery, s.JID, address, session)
+	_, err := s.db.Exec(putSessionQuery, s.JID, address, session, time.Now().Unix())
 	return err
 }
 
@@ -152,7 +182,7 @@ const (
 	getUnuploadedPreKeysQuery   = `SELECT key_id, ` + "`key`" + ` FROM whatsmeow_pre_keys WHERE jid=? AND uploaded=false ORDER BY key_id LIMIT ?`
 	getPreKeyQuery              = `SELECT key_id, ` + "`key`" + ` FROM whatsmeow_pre_keys WHERE jid=? AND key_id=?`
 	deletePreKeyQuery           = `DELETE FROM whatsmeow_pre_keys WHERE jid=? AND key_id=?`
-	markPreKeysAsUploadedQuery  = `UPDATE whatsmeow_pre_keys SET uploaded=true WHERE jid=? AND key_id<=?`
+	markPreKeysAsUploadedQuery  = `UPDATE whatsmeow_pre_keys SET uploaded=true, uploaded_at=? WHERE jid=? AND key_id<=? AND uploaded=false`
 	getUploadedPreKeyCountQuery = `SELECT COUNT(*) FROM whatsmeow_pre_keys WHERE jid=? AND uploaded=true`
 )
 
@@ -247,7 +277,7 @@ func (s *SQLStore) RemovePreKey(id uint32) error {
 }
 
 func (s *SQLStore) MarkPreKeysAsUploaded(upToID uint32) error {
-	_, err := s.db.Exec(markPreKeysAsUploadedQuery, s.JID, upToID)
+	_, err := s.db.Exec(markPreKeysAsUploadedQuery, time.Now().Unix(), s.JID, upToID)
 	return err
 }
 
@@ -674,12 +704,12 @@ func (s *SQLStore) GetAllContacts() (map[types.JID]types.ContactInfo, error) {
 }
 
 const (
-	putChatSettingQuery = `INSERT INTO whatsmeow_chat_settings (our_jid, chat_jid, column_name)
+	putChatSettingQuery = `INSERT INTO whatsmeow_chat_settings (our_jid, chat_jid, %[1]s)
 	VALUES (?, ?, ?)
 	ON DUPLICATE KEY UPDATE
-		column_name = CASE
-			WHEN VALUES(column_name) IS NOT NULL THEN VALUES(column_name)
-			ELSE column_name
+		%[1]s = CASE
+			WHEN VALUES(%[1]s) IS NOT NULL THEN VALUES(%[1]s)
+			ELSE %[1]s
 		END`
 	getChatSettingsQuery = `
 		SELECT muted_until, pinned, archived FROM whatsmeow_chat_settings WHERE our_jid=? AND chat_jid=?
@@ -796,3 +826,756 @@ func (s *SQLStore) GetPrivacyToken(user types.JID) (*store.PrivacyToken, error)
 		return &token, nil
 	}
 }
+
+const (
+	putBroadcastListQuery = `INSERT INTO whatsmeow_broadcast_lists (our_jid, list_jid, name, created_at)
+	VALUES (?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		name = VALUES(name)`
+	getBroadcastListQuery             = `SELECT list_jid, name, created_at FROM whatsmeow_broadcast_lists WHERE our_jid=? AND list_jid=?`
+	getAllBroadcastListsQuery         = `SELECT list_jid, name, created_at FROM whatsmeow_broadcast_lists WHERE our_jid=?`
+	deleteBroadcastListQuery          = `DELETE FROM whatsmeow_broadcast_lists WHERE our_jid=? AND list_jid=?`
+	putBroadcastListRecipientQuery    = `INSERT IGNORE INTO whatsmeow_broadcast_list_recipients (our_jid, list_jid, recipient_jid) VALUES (?, ?, ?)`
+	deleteBroadcastListRecipientQuery = `DELETE FROM whatsmeow_broadcast_list_recipients WHERE our_jid=? AND list_jid=? AND recipient_jid=?`
+	clearBroadcastListRecipientsQuery = `DELETE FROM whatsmeow_broadcast_list_recipients WHERE our_jid=? AND list_jid=?`
+	getBroadcastListRecipientsQuery   = `SELECT recipient_jid FROM whatsmeow_broadcast_list_recipients WHERE our_jid=? AND list_jid=?`
+)
+
+// PutBroadcastList stores the given broadcast list, replacing the name and recipient list if it already exists.
+func (s *SQLStore) PutBroadcastList(list types.BroadcastListInfo) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	_, err = tx.Exec(putBroadcastListQuery, s.JID, list.JID, list.Name, list.CreatedAt.Unix())
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to insert broadcast list: %w", err)
+	}
+	_, err = tx.Exec(clearBroadcastListRecipientsQuery, s.JID, list.JID)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to clear old broadcast list recipients: %w", err)
+	}
+	for _, recipient := range list.Recipients {
+		_, err = tx.Exec(putBroadcastListRecipientQuery, s.JID, list.JID, recipient.ToNonAD())
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to insert broadcast list recipient: %w", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLStore) getBroadcastListRecipients(list types.JID) ([]types.JID, error) {
+	rows, err := s.db.Query(getBroadcastListRecipientsQuery, s.JID, list)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var recipients []types.JID
+	for rows.Next() {
+		var recipient types.JID
+		err = rows.Scan(&recipient)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, rows.Err()
+}
+
+func (s *SQLStore) GetBroadcastList(jid types.JID) (*types.BroadcastListInfo, error) {
+	var list types.BroadcastListInfo
+	var createdAt int64
+	err := s.db.QueryRow(getBroadcastListQuery, s.JID, jid).Scan(&list.JID, &list.Name, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	list.CreatedAt = time.Unix(createdAt, 0)
+	list.Recipients, err = s.getBroadcastListRecipients(list.JID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast list recipients: %w", err)
+	}
+	return &list, nil
+}
+
+func (s *SQLStore) GetAllBroadcastLists() ([]types.BroadcastListInfo, error) {
+	rows, err := s.db.Query(getAllBroadcastListsQuery, s.JID)
+	if err != nil {
+		return nil, err
+	}
+	var lists []types.BroadcastListInfo
+	for rows.Next() {
+		var list types.BroadcastListInfo
+		var createdAt int64
+		err = rows.Scan(&list.JID, &list.Name, &createdAt)
+		if err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		list.CreatedAt = time.Unix(createdAt, 0)
+		lists = append(lists, list)
+	}
+	_ = rows.Close()
+	for i := range lists {
+		lists[i].Recipients, err = s.getBroadcastListRecipients(lists[i].JID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recipients of %s: %w", lists[i].JID, err)
+		}
+	}
+	return lists, nil
+}
+
+func (s *SQLStore) DeleteBroadcastList(jid types.JID) error {
+	_, err := s.db.Exec(deleteBroadcastListQuery, s.JID, jid)
+	return err
+}
+
+func (s *SQLStore) AddBroadcastListRecipients(jid types.JID, recipients []types.JID) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for _, recipient := range recipients {
+		_, err = tx.Exec(putBroadcastListRecipientQuery, s.JID, jid, recipient.ToNonAD())
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLStore) RemoveBroadcastListRecipients(jid types.JID, recipients []types.JID) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for _, recipient := range recipients {
+		_, err = tx.Exec(deleteBroadcastListRecipientQuery, s.JID, jid, recipient.ToNonAD())
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const (
+	putPollQuery = `INSERT INTO whatsmeow_polls (our_jid, chat_jid, poll_id, sender_jid, name, selectable_count, timestamp)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		name = VALUES(name),
+		selectable_count = VALUES(selectable_count)`
+	putPollOptionQuery  = `INSERT IGNORE INTO whatsmeow_poll_options (our_jid, chat_jid, poll_id, option_hash, option_name, idx) VALUES (?, ?, ?, ?, ?, ?)`
+	getPollQuery        = `SELECT sender_jid, name, selectable_count, timestamp FROM whatsmeow_polls WHERE our_jid=? AND chat_jid=? AND poll_id=?`
+	getPollOptionsQuery = `
+		SELECT option_hash, option_name FROM whatsmeow_poll_options WHERE our_jid=? AND chat_jid=? AND poll_id=? ORDER BY idx
+	`
+	// Votes can arrive out of order (e.g. from history syncs), so only replace the old vote if the new one is newer.
+	putPollVoteQuery = `INSERT INTO whatsmeow_poll_votes (our_jid, chat_jid, poll_id, voter_jid, selected_options, timestamp)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		selected_options = IF(VALUES(timestamp) >= timestamp, VALUES(selected_options), selected_options),
+		timestamp = IF(VALUES(timestamp) >= timestamp, VALUES(timestamp), timestamp)`
+	getPollVotesQuery = `SELECT voter_jid, selected_options, timestamp FROM whatsmeow_poll_votes WHERE our_jid=? AND chat_jid=? AND poll_id=?`
+)
+
+func (s *SQLStore) PutPoll(poll types.PollInfo) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	chat := poll.Chat.ToNonAD()
+	_, err = tx.Exec(putPollQuery, s.JID, chat, poll.ID, poll.Sender.ToNonAD(), poll.Name, poll.SelectableOptionsCount, poll.Timestamp.Unix())
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to insert poll: %w", err)
+	}
+	for i, option := range poll.Options {
+		_, err = tx.Exec(putPollOptionQuery, s.JID, chat, poll.ID, hex.EncodeToString(option.Hash), option.Name, i)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to insert poll option: %w", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLStore) GetPoll(chat types.JID, id types.MessageID) (*types.PollInfo, error) {
+	poll := types.PollInfo{Chat: chat.ToNonAD(), ID: id}
+	var ts int64
+	err := s.db.QueryRow(getPollQuery, s.JID, poll.Chat, id).Scan(&poll.Sender, &poll.Name, &poll.SelectableOptionsCount, &ts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	poll.Timestamp = time.Unix(ts, 0)
+	rows, err := s.db.Query(getPollOptionsQuery, s.JID, poll.Chat, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query poll options: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hash string
+		var option types.PollOption
+		err = rows.Scan(&hash, &option.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		option.Hash, err = hex.DecodeString(hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid poll option hash in database: %w", err)
+		}
+		poll.Options = append(poll.Options, option)
+	}
+	return &poll, rows.Err()
+}
+
+func (s *SQLStore) PutPollVote(chat types.JID, pollID types.MessageID, vote types.PollVote) error {
+	selected := make([]string, len(vote.SelectedOptions))
+	for i, hash := range vote.SelectedOptions {
+		selected[i] = hex.EncodeToString(hash)
+	}
+	_, err := s.db.Exec(putPollVoteQuery, s.JID, chat.ToNonAD(), pollID, vote.Voter.ToNonAD(), strings.Join(selected, ","), vote.Timestamp.UnixMilli())
+	return err
+}
+
+func (s *SQLStore) GetPollVotes(chat types.JID, pollID types.MessageID) ([]types.PollVote, error) {
+	rows, err := s.db.Query(getPollVotesQuery, s.JID, chat.ToNonAD(), pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var votes []types.PollVote
+	for rows.Next() {
+		var vote types.PollVote
+		var selected string
+		var ts int64
+		err = rows.Scan(&vote.Voter, &selected, &ts)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		vote.Timestamp = time.UnixMilli(ts)
+		if selected != "" {
+			for _, hash := range strings.Split(selected, ",") {
+				decoded, err := hex.DecodeString(hash)
+				if err != nil {
+					return nil, fmt.Errorf("invalid poll option hash in database: %w", err)
+				}
+				vote.SelectedOptions = append(vote.SelectedOptions, decoded)
+			}
+		}
+		votes = append(votes, vote)
+	}
+	return votes, rows.Err()
+}
+
+const (
+	// Removals are stored as empty reactions, so that older reactions arriving late don't resurrect removed ones.
+	putReactionQuery = `INSERT INTO whatsmeow_reactions (our_jid, chat_jid, message_id, sender_jid, text, timestamp)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		text = IF(VALUES(timestamp) >= timestamp, VALUES(text), text),
+		timestamp = IF(VALUES(timestamp) >= timestamp, VALUES(timestamp), timestamp)`
+	getReactionsQuery = `
+		SELECT sender_jid, text, timestamp FROM whatsmeow_reactions
+		WHERE our_jid=? AND chat_jid=? AND message_id=? AND text<>''
+		ORDER BY timestamp
+	`
+)
+
+func (s *SQLStore) PutReaction(reaction types.Reaction) error {
+	_, err := s.db.Exec(putReactionQuery, s.JID, reaction.Chat.ToNonAD(), reaction.MessageID, reaction.Sender.ToNonAD(), reaction.Text, reaction.Timestamp.UnixMilli())
+	return err
+}
+
+func (s *SQLStore) GetReactions(chat types.JID, messageID types.MessageID) ([]types.Reaction, error) {
+	chat = chat.ToNonAD()
+	rows, err := s.db.Query(getReactionsQuery, s.JID, chat, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var reactions []types.Reaction
+	for rows.Next() {
+		reaction := types.Reaction{Chat: chat, MessageID: messageID}
+		var ts int64
+		err = rows.Scan(&reaction.Sender, &reaction.Text, &ts)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		reaction.Timestamp = time.UnixMilli(ts)
+		reactions = append(reactions, reaction)
+	}
+	return reactions, rows.Err()
+}
+
+const (
+	putMessageVersionQuery  = `INSERT IGNORE INTO whatsmeow_message_versions (our_jid, chat_jid, message_id, edit_id, sender_jid, edit_ts, content) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	getMessageVersionsQuery = `
+		SELECT edit_id, sender_jid, edit_ts, content FROM whatsmeow_message_versions
+		WHERE our_jid=? AND chat_jid=? AND message_id=?
+		ORDER BY edit_ts
+	`
+)
+
+func (s *SQLStore) PutMessageVersion(version types.MessageVersion) error {
+	content, err := proto.Marshal(version.Content)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message content: %w", err)
+	}
+	_, err = s.db.Exec(putMessageVersionQuery, s.JID, version.Chat.ToNonAD(), version.ID, version.EditID, version.Sender.ToNonAD(), version.EditTS.UnixMilli(), content)
+	return err
+}
+
+func (s *SQLStore) GetMessageVersions(chat types.JID, id types.MessageID) ([]types.MessageVersion, error) {
+	chat = chat.ToNonAD()
+	rows, err := s.db.Query(getMessageVersionsQuery, s.JID, chat, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var versions []types.MessageVersion
+	for rows.Next() {
+		version := types.MessageVersion{Chat: chat, ID: id}
+		var ts int64
+		var content []byte
+		err = rows.Scan(&version.EditID, &version.Sender, &ts, &content)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		version.EditTS = time.UnixMilli(ts)
+		version.Content = &waProto.Message{}
+		err = proto.Unmarshal(content, version.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message content: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+const (
+	putSentMessageQuery = `INSERT INTO whatsmeow_sent_messages (our_jid, chat_jid, message_id, recipients, sent_at) VALUES (?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE recipients = VALUES(recipients)`
+	// Only the first receipt of each type from each user matters, so later ones are ignored.
+	putMessageReceiptQuery  = `INSERT IGNORE INTO whatsmeow_message_receipts (our_jid, chat_jid, message_id, user_jid, receipt_type, timestamp) VALUES (?, ?, ?, ?, ?, ?)`
+	getSentMessageQuery     = `SELECT recipients, sent_at FROM whatsmeow_sent_messages WHERE our_jid=? AND chat_jid=? AND message_id=?`
+	getMessageReceiptsQuery = `SELECT user_jid, receipt_type, timestamp FROM whatsmeow_message_receipts WHERE our_jid=? AND chat_jid=? AND message_id=?`
+)
+
+func (s *SQLStore) PutSentMessage(chat types.JID, id types.MessageID, recipients int, sentAt time.Time) error {
+	_, err := s.db.Exec(putSentMessageQuery, s.JID, chat.ToNonAD(), id, recipients, sentAt.UnixMilli())
+	return err
+}
+
+func (s *SQLStore) PutMessageReceipt(chat types.JID, id types.MessageID, user types.JID, receiptType types.ReceiptType, ts time.Time) error {
+	_, err := s.db.Exec(putMessageReceiptQuery, s.JID, chat.ToNonAD(), id, user.ToNonAD(), string(receiptType), ts.UnixMilli())
+	return err
+}
+
+func (s *SQLStore) GetMessageStatus(chat types.JID, id types.MessageID) (*types.MessageStatusInfo, error) {
+	chat = chat.ToNonAD()
+	status := types.MessageStatusInfo{
+		Chat:        chat,
+		ID:          id,
+		DeliveredTo: make(map[types.JID]time.Time),
+		ReadBy:      make(map[types.JID]time.Time),
+		PlayedBy:    make(map[types.JID]time.Time),
+	}
+	var sentAt int64
+	err := s.db.QueryRow(getSentMessageQuery, s.JID, chat, id).Scan(&status.Recipients, &sentAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	status.SentAt = time.UnixMilli(sentAt)
+	rows, err := s.db.Query(getMessageReceiptsQuery, s.JID, chat, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query message receipts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var user types.JID
+		var receiptType string
+		var ts int64
+		err = rows.Scan(&user, &receiptType, &ts)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		switch types.ReceiptType(receiptType) {
+		case types.ReceiptTypeDelivered:
+			status.DeliveredTo[user] = time.UnixMilli(ts)
+		case types.ReceiptTypeRead:
+			status.ReadBy[user] = time.UnixMilli(ts)
+		case types.ReceiptTypePlayed:
+			status.PlayedBy[user] = time.UnixMilli(ts)
+		}
+	}
+	return &status, rows.Err()
+}
+
+const (
+	putDisappearingTimerQuery = `INSERT INTO whatsmeow_disappearing_timers (our_jid, chat_jid, timer, setting_ts) VALUES (?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		timer = IF(VALUES(setting_ts) >= setting_ts, VALUES(timer), timer),
+		setting_ts = IF(VALUES(setting_ts) >= setting_ts, VALUES(setting_ts), setting_ts)`
+	getDisappearingTimerQuery  = `SELECT timer, setting_ts FROM whatsmeow_disappearing_timers WHERE our_jid=? AND chat_jid=?`
+	putMessageExpirationQuery  = `INSERT IGNORE INTO whatsmeow_message_expirations (our_jid, chat_jid, message_id, expires_at) VALUES (?, ?, ?, ?)`
+	getExpiredMessagesQuery    = `SELECT chat_jid, message_id, expires_at FROM whatsmeow_message_expirations WHERE our_jid=? AND expires_at<=?`
+	deleteMessageExpiryQuery   = `DELETE FROM whatsmeow_message_expirations WHERE our_jid=? AND chat_jid=? AND message_id=?`
+	deleteExpiredVersionsQuery = `DELETE FROM whatsmeow_message_versions WHERE our_jid=? AND chat_jid=? AND message_id=?`
+	deleteExpiredPollQuery     = `DELETE FROM whatsmeow_polls WHERE our_jid=? AND chat_jid=? AND poll_id=?`
+	deleteExpiredVotesQuery    = `DELETE FROM whatsmeow_poll_votes WHERE our_jid=? AND chat_jid=? AND poll_id=?`
+	deleteExpiredReactsQuery   = `DELETE FROM whatsmeow_reactions WHERE our_jid=? AND chat_jid=? AND message_id=?`
+	deleteExpiredSentQuery     = `DELETE FROM whatsmeow_sent_messages WHERE our_jid=? AND chat_jid=? AND message_id=?`
+)
+
+func (s *SQLStore) PutDisappearingTimer(timer types.DisappearingTimer) error {
+	_, err := s.db.Exec(putDisappearingTimerQuery, s.JID, timer.Chat.ToNonAD(), int64(timer.Timer.Seconds()), timer.SettingTimestamp.Unix())
+	return err
+}
+
+func (s *SQLStore) GetDisappearingTimer(chat types.JID) (timer types.DisappearingTimer, err error) {
+	timer.Chat = chat.ToNonAD()
+	var seconds, settingTS int64
+	err = s.db.QueryRow(getDisappearingTimerQuery, s.JID, timer.Chat).Scan(&seconds, &settingTS)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	} else if err == nil {
+		timer.Timer = time.Duration(seconds) * time.Second
+		timer.SettingTimestamp = time.Unix(settingTS, 0)
+	}
+	return
+}
+
+func (s *SQLStore) PutMessageExpiration(msg types.ExpiringMessage) error {
+	_, err := s.db.Exec(putMessageExpirationQuery, s.JID, msg.Chat.ToNonAD(), msg.ID, msg.ExpiresAt.Unix())
+	return err
+}
+
+func (s *SQLStore) getExpiredMessages(before time.Time) ([]types.ExpiringMessage, error) {
+	rows, err := s.db.Query(getExpiredMessagesQuery, s.JID, before.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var expired []types.ExpiringMessage
+	for rows.Next() {
+		var msg types.ExpiringMessage
+		var expiresAt int64
+		err = rows.Scan(&msg.Chat, &msg.ID, &expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		msg.ExpiresAt = time.Unix(expiresAt, 0)
+		expired = append(expired, msg)
+	}
+	return expired, rows.Err()
+}
+
+// DeleteExpiredMessages deletes all locally stored data (edit history, polls, reactions and receipts)
+// of messages that expired before the given time, and returns the list of deleted messages.
+func (s *SQLStore) DeleteExpiredMessages(before time.Time) ([]types.ExpiringMessage, error) {
+	expired, err := s.getExpiredMessages(before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired messages: %w", err)
+	} else if len(expired) == 0 {
+		return nil, nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	queries := []string{
+		deleteExpiredVersionsQuery, deleteExpiredPollQuery, deleteExpiredVotesQuery,
+		deleteExpiredReactsQuery, deleteExpiredSentQuery, deleteMessageExpiryQuery,
+	}
+	for _, msg := range expired {
+		for _, query := range queries {
+			_, err = tx.Exec(query, s.JID, msg.Chat, msg.ID)
+			if err != nil {
+				_ = tx.Rollback()
+				return nil, fmt.Errorf("failed to delete data of expired message %s: %w", msg.ID, err)
+			}
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return expired, nil
+}
+
+const (
+	putFailedHistorySyncQuery = `
+		INSERT INTO whatsmeow_history_sync_failures
+			(our_jid, message_id, chat_jid, sender_jid, notification, sync_type, chunk_order, attempts, last_error, failed_at, next_retry, streamed_conversations)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			notification = VALUES(notification),
+			attempts = VALUES(attempts),
+			last_error = VALUES(last_error),
+			next_retry = VALUES(next_retry),
+			streamed_conversations = VALUES(streamed_conversations)
+	`
+	getFailedHistorySyncsQuery = `
+		SELECT message_id, chat_jid, sender_jid, notification, attempts, last_error, failed_at, next_retry, streamed_conversations
+		FROM whatsmeow_history_sync_failures WHERE our_jid=?
+	`
+	getFailedHistorySyncQuery    = getFailedHistorySyncsQuery + ` AND message_id=?`
+	deleteFailedHistorySyncQuery = `DELETE FROM whatsmeow_history_sync_failures WHERE our_jid=? AND message_id=?`
+)
+
+func (s *SQLStore) PutFailedHistorySync(failure store.FailedHistorySync) error {
+	notif, err := proto.Marshal(failure.Notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history sync notification: %w", err)
+	}
+	var nextRetry int64
+	if !failure.NextRetry.IsZero() {
+		nextRetry = failure.NextRetry.Unix()
+	}
+	_, err = s.db.Exec(putFailedHistorySyncQuery,
+		s.JID, failure.MessageID, failure.Chat, failure.Sender, notif,
+		int(failure.Notification.GetSyncType()), failure.Notification.GetChunkOrder(),
+		failure.Attempts, failure.LastError, failure.FailedAt.Unix(), nextRetry, failure.StreamedConversations,
+	)
+	return err
+}
+
+func (s *SQLStore) scanFailedHistorySync(row scannable) (*store.FailedHistorySync, error) {
+	var failure store.FailedHistorySync
+	var notif []byte
+	var failedAt, nextRetry int64
+	err := row.Scan(&failure.MessageID, &failure.Chat, &failure.Sender, &notif, &failure.Attempts, &failure.LastError, &failedAt, &nextRetry, &failure.StreamedConversations)
+	if err != nil {
+		return nil, err
+	}
+	failure.Notification = &waProto.HistorySyncNotification{}
+	err = proto.Unmarshal(notif, failure.Notification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal history sync notification: %w", err)
+	}
+	failure.FailedAt = time.Unix(failedAt, 0)
+	if nextRetry != 0 {
+		failure.NextRetry = time.Unix(nextRetry, 0)
+	}
+	return &failure, nil
+}
+
+func (s *SQLStore) GetFailedHistorySync(messageID types.MessageID) (*store.FailedHistorySync, error) {
+	failure, err := s.scanFailedHistorySync(s.db.QueryRow(getFailedHistorySyncQuery, s.JID, messageID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return failure, err
+}
+
+func (s *SQLStore) GetFailedHistorySyncs() ([]store.FailedHistorySync, error) {
+	rows, err := s.db.Query(getFailedHistorySyncsQuery, s.JID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var failures []store.FailedHistorySync
+	for rows.Next() {
+		failure, err := s.scanFailedHistorySync(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		failures = append(failures, *failure)
+	}
+	return failures, rows.Err()
+}
+
+func (s *SQLStore) DeleteFailedHistorySync(messageID types.MessageID) error {
+	_, err := s.db.Exec(deleteFailedHistorySyncQuery, s.JID, messageID)
+	return err
+}
+
+const (
+	putVerifiedIdentityQuery = `
+		INSERT INTO whatsmeow_identity_verifications (our_jid, their_jid, identity, verified_at)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			identity = VALUES(identity),
+			verified_at = VALUES(verified_at)
+	`
+	getVerifiedIdentityQuery    = `SELECT identity, verified_at FROM whatsmeow_identity_verifications WHERE our_jid=? AND their_jid=?`
+	deleteVerifiedIdentityQuery = `DELETE FROM whatsmeow_identity_verifications WHERE our_jid=? AND their_jid=?`
+)
+
+func (s *SQLStore) PutVerifiedIdentity(identity store.VerifiedIdentity) error {
+	_, err := s.db.Exec(putVerifiedIdentityQuery, s.JID, identity.JID.ToNonAD(), identity.IdentityKey[:], identity.VerifiedAt.Unix())
+	return err
+}
+
+func (s *SQLStore) GetVerifiedIdentity(user types.JID) (*store.VerifiedIdentity, error) {
+	user = user.ToNonAD()
+	var key []byte
+	var verifiedAt int64
+	err := s.db.QueryRow(getVerifiedIdentityQuery, s.JID, user).Scan(&key, &verifiedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else if len(key) != 32 {
+		return nil, ErrInvalidLength
+	}
+	return &store.VerifiedIdentity{
+		JID:         user,
+		IdentityKey: *(*[32]byte)(key),
+		VerifiedAt:  time.Unix(verifiedAt, 0),
+	}, nil
+}
+
+func (s *SQLStore) DeleteVerifiedIdentity(user types.JID) error {
+	_, err := s.db.Exec(deleteVerifiedIdentityQuery, s.JID, user.ToNonAD())
+	return err
+}
+
+const (
+	putSignedPreKeyQuery = `
+		INSERT INTO whatsmeow_signed_pre_keys (jid, key_id, priv_key, signature, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			priv_key = VALUES(priv_key),
+			signature = VALUES(signature),
+			created_at = VALUES(created_at),
+			retired_at = 0
+	`
+	getSignedPreKeyQuery            = `SELECT key_id, priv_key, signature, created_at, retired_at FROM whatsmeow_signed_pre_keys WHERE jid=? AND key_id=?`
+	retireSignedPreKeyQuery         = `UPDATE whatsmeow_signed_pre_keys SET retired_at=? WHERE jid=? AND key_id=? AND retired_at=0`
+	deleteRetiredSignedPreKeysQuery = `DELETE FROM whatsmeow_signed_pre_keys WHERE jid=? AND retired_at>0 AND retired_at<?`
+	deleteUploadedPreKeysQuery      = `DELETE FROM whatsmeow_pre_keys WHERE jid=? AND uploaded=true AND uploaded_at>0 AND uploaded_at<?`
+	getPreKeyCountsQuery            = `
+		SELECT COALESCE(SUM(CASE WHEN uploaded THEN 1 ELSE 0 END), 0), COALESCE(SUM(CASE WHEN uploaded THEN 0 ELSE 1 END), 0)
+		FROM whatsmeow_pre_keys WHERE jid=?
+	`
+)
+
+func (s *SQLStore) PutSignedPreKey(key *keys.PreKey, createdAt time.Time) error {
+	_, err := s.db.Exec(putSignedPreKeyQuery, s.JID, key.KeyID, key.Priv[:], key.Signature[:], createdAt.Unix())
+	return err
+}
+
+func (s *SQLStore) GetSignedPreKey(id uint32) (*store.SignedPreKeyInfo, error) {
+	var keyID uint32
+	var priv, signature []byte
+	var createdAt, retiredAt int64
+	err := s.db.QueryRow(getSignedPreKeyQuery, s.JID, id).Scan(&keyID, &priv, &signature, &createdAt, &retiredAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else if len(priv) != 32 || len(signature) != 64 {
+		return nil, ErrInvalidLength
+	}
+	info := &store.SignedPreKeyInfo{
+		Key: &keys.PreKey{
+			KeyPair:   *keys.NewKeyPairFromPrivateKey(*(*[32]byte)(priv)),
+			KeyID:     keyID,
+			Signature: (*[64]byte)(signature),
+		},
+		CreatedAt: time.Unix(createdAt, 0),
+	}
+	if retiredAt != 0 {
+		info.RetiredAt = time.Unix(retiredAt, 0)
+	}
+	return info, nil
+}
+
+func (s *SQLStore) RetireSignedPreKey(id uint32, retiredAt time.Time) error {
+	_, err := s.db.Exec(retireSignedPreKeyQuery, retiredAt.Unix(), s.JID, id)
+	return err
+}
+
+func (s *SQLStore) DeleteRetiredSignedPreKeys(retiredBefore time.Time) (int64, error) {
+	res, err := s.db.Exec(deleteRetiredSignedPreKeysQuery, s.JID, retiredBefore.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *SQLStore) DeleteUploadedPreKeys(uploadedBefore time.Time) (int64, error) {
+	s.preKeyLock.Lock()
+	defer s.preKeyLock.Unlock()
+	res, err := s.db.Exec(deleteUploadedPreKeysQuery, s.JID, uploadedBefore.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *SQLStore) GetPreKeyCounts() (uploaded, unuploaded int, err error) {
+	err = s.db.QueryRow(getPreKeyCountsQuery, s.JID).Scan(&uploaded, &unuploaded)
+	return
+}
+
+const (
+	getSessionAddressesQuery = `SELECT their_id FROM whatsmeow_sessions WHERE our_jid=?`
+	getStaleSessionsQuery    = `SELECT their_id FROM whatsmeow_sessions WHERE our_jid=? AND updated_at<?`
+	getStaleIdentitiesQuery  = `
+		SELECT their_id FROM whatsmeow_identity_keys
+		WHERE our_jid=? AND updated_at<? AND their_id NOT IN (
+			SELECT their_id FROM whatsmeow_sessions WHERE our_jid=? AND updated_at>=?
+		)
+	`
+	getSenderKeyChatsQuery      = `SELECT DISTINCT chat_id FROM whatsmeow_sender_keys WHERE our_jid=?`
+	deleteSenderKeysInChatQuery = `DELETE FROM whatsmeow_sender_keys WHERE our_jid=? AND chat_id=?`
+)
+
+func (s *SQLStore) scanStrings(query string, args ...interface{}) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func (s *SQLStore) GetSessionAddresses() ([]string, error) {
+	return s.scanStrings(getSessionAddressesQuery, s.JID)
+}
+
+func (s *SQLStore) GetStaleSessions(unusedSince time.Time) ([]string, error) {
+	return s.scanStrings(getStaleSessionsQuery, s.JID, unusedSince.Unix())
+}
+
+func (s *SQLStore) GetStaleIdentities(unusedSince time.Time) ([]string, error) {
+	return s.scanStrings(getStaleIdentitiesQuery, s.JID, unusedSince.Unix(), s.JID, unusedSince.Unix())
+}
+
+func (s *SQLStore) GetSenderKeyChats() ([]string, error) {
+	return s.scanStrings(getSenderKeyChatsQuery, s.JID)
+}
+
+func (s *SQLStore) DeleteSenderKeysInChat(chat string) error {
+	_, err := s.db.Exec(deleteSenderKeysInChatQuery, s.JID, chat)
+	return err
+}
@@ -9,6 +9,7 @@ package sqlstore
 import (
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 type upgradeFunc func(*sql.Tx, *Container) error
@@ -17,7 +18,7 @@ type upgradeFunc func(*sql.Tx, *Container) error
 //
 // This may be of use if you want to manage the database fully manually, but in most cases you
 // should just call Container.Upgrade to let the library handle everything.
-var Upgrades = [...]upgradeFunc{upgradeV1, upgradeV2, upgradeV3, upgradeV4, upgradeV5, upgradeV6}
+var Upgrades = [...]upgradeFunc{upgradeV1, upgradeV2, upgradeV3, upgradeV4, upgradeV5, upgradeV6, upgradeV7, upgradeV8, upgradeV9, upgradeV10, upgradeV11, upgradeV12, upgradeV13, upgradeV14, upgradeV15, upgradeV16, upgradeV17}
 
 func (c *Container) getVersion() (int, error) {
 	_, err := c.db.Exec("CREATE TABLE IF NOT EXISTS whatsmeow_version (version INTEGER)")
@@ -277,3 +278,219 @@ func upgradeV6(tx *sql.Tx, container *Container) error {
 	_, err := tx.Exec("ALTER TABLE whatsmeow_device ADD COLUMN facebook_uuid varchar(100)")
 	return err
 }
+
+func upgradeV7(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec(`CREATE TABLE whatsmeow_broadcast_lists (
+		our_jid    VARCHAR(255),
+		list_jid   VARCHAR(255),
+		name       TEXT   NOT NULL,
+		created_at BIGINT NOT NULL,
+		PRIMARY KEY (our_jid, list_jid),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`CREATE TABLE whatsmeow_broadcast_list_recipients (
+		our_jid       VARCHAR(255),
+		list_jid      VARCHAR(255),
+		recipient_jid VARCHAR(255),
+		PRIMARY KEY (our_jid, list_jid, recipient_jid),
+		FOREIGN KEY (our_jid, list_jid) REFERENCES whatsmeow_broadcast_lists(our_jid, list_jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	return err
+}
+
+func upgradeV8(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec(`CREATE TABLE whatsmeow_polls (
+		our_jid          VARCHAR(255),
+		chat_jid         VARCHAR(100),
+		poll_id          VARCHAR(64),
+		sender_jid       VARCHAR(255) NOT NULL,
+		name             TEXT   NOT NULL,
+		selectable_count INTEGER NOT NULL,
+		timestamp        BIGINT NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid, poll_id),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`CREATE TABLE whatsmeow_poll_options (
+		our_jid     VARCHAR(255),
+		chat_jid    VARCHAR(100),
+		poll_id     VARCHAR(64),
+		option_hash VARCHAR(64),
+		option_name TEXT    NOT NULL,
+		idx         INTEGER NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid, poll_id, option_hash),
+		FOREIGN KEY (our_jid, chat_jid, poll_id) REFERENCES whatsmeow_polls(our_jid, chat_jid, poll_id) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`CREATE TABLE whatsmeow_poll_votes (
+		our_jid          VARCHAR(255),
+		chat_jid         VARCHAR(100),
+		poll_id          VARCHAR(64),
+		voter_jid        VARCHAR(100),
+		selected_options TEXT   NOT NULL,
+		timestamp        BIGINT NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid, poll_id, voter_jid),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	return err
+}
+
+func upgradeV9(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec(`CREATE TABLE whatsmeow_reactions (
+		our_jid    VARCHAR(255),
+		chat_jid   VARCHAR(100),
+		message_id VARCHAR(64),
+		sender_jid VARCHAR(100),
+		text       TEXT   NOT NULL,
+		timestamp  BIGINT NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid, message_id, sender_jid),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	return err
+}
+
+func upgradeV10(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec(`CREATE TABLE whatsmeow_message_versions (
+		our_jid    VARCHAR(255),
+		chat_jid   VARCHAR(100),
+		message_id VARCHAR(64),
+		edit_id    VARCHAR(64),
+		sender_jid VARCHAR(255) NOT NULL,
+		edit_ts    BIGINT NOT NULL,
+		content    TEXT   NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid, message_id, edit_id),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	return err
+}
+
+func upgradeV11(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec(`CREATE TABLE whatsmeow_sent_messages (
+		our_jid    VARCHAR(255),
+		chat_jid   VARCHAR(100),
+		message_id VARCHAR(64),
+		recipients INTEGER NOT NULL,
+		sent_at    BIGINT  NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid, message_id),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`CREATE TABLE whatsmeow_message_receipts (
+		our_jid      VARCHAR(255),
+		chat_jid     VARCHAR(100),
+		message_id   VARCHAR(64),
+		user_jid     VARCHAR(100),
+		receipt_type VARCHAR(16),
+		timestamp    BIGINT NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid, message_id, user_jid, receipt_type),
+		FOREIGN KEY (our_jid, chat_jid, message_id) REFERENCES whatsmeow_sent_messages(our_jid, chat_jid, message_id) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	return err
+}
+
+func upgradeV12(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec(`CREATE TABLE whatsmeow_disappearing_timers (
+		our_jid    VARCHAR(255),
+		chat_jid   VARCHAR(255),
+		timer      BIGINT NOT NULL,
+		setting_ts BIGINT NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`CREATE TABLE whatsmeow_message_expirations (
+		our_jid    VARCHAR(255),
+		chat_jid   VARCHAR(255),
+		message_id VARCHAR(255),
+		expires_at BIGINT NOT NULL,
+		PRIMARY KEY (our_jid, chat_jid, message_id),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`CREATE INDEX whatsmeow_message_expirations_expires_at_idx ON whatsmeow_message_expirations (our_jid, expires_at)`)
+	return err
+}
+
+func upgradeV13(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec(`CREATE TABLE whatsmeow_history_sync_failures (
+		our_jid      VARCHAR(255),
+		message_id   VARCHAR(255),
+		chat_jid     VARCHAR(255) NOT NULL,
+		sender_jid   VARCHAR(255) NOT NULL,
+		notification TEXT    NOT NULL,
+		sync_type    INTEGER NOT NULL,
+		chunk_order  INTEGER NOT NULL,
+		attempts     INTEGER NOT NULL,
+		last_error   TEXT    NOT NULL,
+		failed_at    BIGINT  NOT NULL,
+		next_retry   BIGINT  NOT NULL,
+		PRIMARY KEY (our_jid, message_id),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	return err
+}
+
+func upgradeV14(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec(`CREATE TABLE whatsmeow_identity_verifications (
+		our_jid     VARCHAR(255),
+		their_jid   VARCHAR(255),
+		identity    TEXT   NOT NULL CHECK ( length(identity) = 32 ),
+		verified_at BIGINT NOT NULL,
+		PRIMARY KEY (our_jid, their_jid),
+		FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	return err
+}
+
+func upgradeV15(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec(`CREATE TABLE whatsmeow_signed_pre_keys (
+		jid        VARCHAR(255),
+		key_id     INTEGER NOT NULL CHECK ( key_id >= 0 AND key_id < 16777216 ),
+		priv_key   TEXT    NOT NULL CHECK ( length(priv_key) = 32 ),
+		signature  TEXT    NOT NULL CHECK ( length(signature) = 64 ),
+		created_at BIGINT  NOT NULL,
+		retired_at BIGINT  NOT NULL DEFAULT 0,
+		PRIMARY KEY (jid, key_id),
+		FOREIGN KEY (jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+	)`)
+	if err != nil {
+		return err
+	}
+	// Existing uploaded keys don't have a known upload time, so they're left at 0 and never pruned
+	_, err = tx.Exec("ALTER TABLE whatsmeow_pre_keys ADD COLUMN uploaded_at BIGINT NOT NULL DEFAULT 0")
+	return err
+}
+
+func upgradeV16(tx *sql.Tx, container *Container) error {
+	now := time.Now().Unix()
+	for _, table := range []string{"whatsmeow_sessions", "whatsmeow_identity_keys"} {
+		_, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN updated_at BIGINT NOT NULL DEFAULT 0", table))
+		if err != nil {
+			return err
+		}
+		// The last use of existing rows isn't known, so treat them as used now
+		_, err = tx.Exec(fmt.Sprintf("UPDATE %s SET updated_at=?", table), now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upgradeV17(tx *sql.Tx, container *Container) error {
+	_, err := tx.Exec("ALTER TABLE whatsmeow_history_sync_failures ADD COLUMN streamed_conversations INTEGER NOT NULL DEFAULT 0")
+	return err
+}
@@ -26,6 +26,12 @@ type IdentityStore interface {
 	IsTrustedIdentity(address string, key [32]byte) (bool, error)
 }
 
+// IdentityGetter is an optional interface for IdentityStores that can return the stored identity key of an address.
+// Verifying identities (see whatsmeow.Client.MarkIdentityTrusted) requires the identity store to implement this.
+type IdentityGetter interface {
+	GetIdentity(address string) (key [32]byte, found bool, err error)
+}
+
 type SessionStore interface {
 	GetSession(address string) ([]byte, error)
 	HasSession(address string) (bool, error)
@@ -126,6 +132,114 @@ type PrivacyTokenStore interface {
 	GetPrivacyToken(user types.JID) (*PrivacyToken, error)
 }
 
+type BroadcastListStore interface {
+	PutBroadcastList(list types.BroadcastListInfo) error
+	GetBroadcastList(jid types.JID) (*types.BroadcastListInfo, error)
+	GetAllBroadcastLists() ([]types.BroadcastListInfo, error)
+	DeleteBroadcastList(jid types.JID) error
+	AddBroadcastListRecipients(jid types.JID, recipients []types.JID) error
+	RemoveBroadcastListRecipients(jid types.JID, recipients []types.JID) error
+}
+
+type PollStore interface {
+	PutPoll(poll types.PollInfo) error
+	GetPoll(chat types.JID, id types.MessageID) (*types.PollInfo, error)
+	PutPollVote(chat types.JID, pollID types.MessageID, vote types.PollVote) error
+	GetPollVotes(chat types.JID, pollID types.MessageID) ([]types.PollVote, error)
+}
+
+type ReactionStore interface {
+	PutReaction(reaction types.Reaction) error
+	GetReactions(chat types.JID, messageID types.MessageID) ([]types.Reaction, error)
+}
+
+type EditHistoryStore interface {
+	PutMessageVersion(version types.MessageVersion) error
+	GetMessageVersions(chat types.JID, id types.MessageID) ([]types.MessageVersion, error)
+}
+
+type MessageStatusStore interface {
+	PutSentMessage(chat types.JID, id types.MessageID, recipients int, sentAt time.Time) error
+	PutMessageReceipt(chat types.JID, id types.MessageID, user types.JID, receiptType types.ReceiptType, ts time.Time) error
+	GetMessageStatus(chat types.JID, id types.MessageID) (*types.MessageStatusInfo, error)
+}
+
+type DisappearingTimerStore interface {
+	PutDisappearingTimer(timer types.DisappearingTimer) error
+	GetDisappearingTimer(chat types.JID) (types.DisappearingTimer, error)
+	PutMessageExpiration(msg types.ExpiringMessage) error
+	DeleteExpiredMessages(before time.Time) ([]types.ExpiringMessage, error)
+}
+
+type MessageSearchStore interface {
+	IndexMessages(entries ...types.MessageSearchEntry) error
+	DeleteIndexedMessage(chat types.JID, id types.MessageID) error
+	SearchMessages(query types.MessageSearchQuery) ([]types.MessageSearchEntry, error)
+}
+
+// FailedHistorySync is a history sync notification whose blob couldn't be downloaded or decoded.
+type FailedHistorySync struct {
+	MessageID    types.MessageID
+	Chat         types.JID
+	Sender       types.JID
+	Notification *waProto.HistorySyncNotification
+	Attempts     int
+	LastError    string
+	FailedAt     time.Time
+	// The time when the download should be retried next. Zero if it won't be retried automatically anymore.
+	NextRetry time.Time
+	// The number of conversations that were already dispatched before decoding a streamed history sync failed.
+	// Retries skip this many conversations, so that they're not dispatched again.
+	StreamedConversations int
+}
+
+type HistorySyncFailureStore interface {
+	PutFailedHistorySync(failure FailedHistorySync) error
+	GetFailedHistorySync(messageID types.MessageID) (*FailedHistorySync, error)
+	GetFailedHistorySyncs() ([]FailedHistorySync, error)
+	DeleteFailedHistorySync(messageID types.MessageID) error
+}
+
+// VerifiedIdentity is an identity key that the user has manually verified (e.g. by comparing safety numbers).
+type VerifiedIdentity struct {
+	JID         types.JID
+	IdentityKey [32]byte
+	VerifiedAt  time.Time
+}
+
+type IdentityVerificationStore interface {
+	PutVerifiedIdentity(identity VerifiedIdentity) error
+	GetVerifiedIdentity(user types.JID) (*VerifiedIdentity, error)
+	DeleteVerifiedIdentity(user types.JID) error
+}
+
+// SignedPreKeyInfo contains a signed prekey along with the times it was created and replaced.
+type SignedPreKeyInfo struct {
+	Key       *keys.PreKey
+	CreatedAt time.Time
+	// The time when the key was replaced by a newer one. Zero if the key is still in use.
+	RetiredAt time.Time
+}
+
+// PreKeyMaintenanceStore contains methods for rotating the signed prekey and cleaning up old one-time prekeys.
+type PreKeyMaintenanceStore interface {
+	PutSignedPreKey(key *keys.PreKey, createdAt time.Time) error
+	GetSignedPreKey(id uint32) (*SignedPreKeyInfo, error)
+	RetireSignedPreKey(id uint32, retiredAt time.Time) error
+	DeleteRetiredSignedPreKeys(retiredBefore time.Time) (int64, error)
+	DeleteUploadedPreKeys(uploadedBefore time.Time) (int64, error)
+	GetPreKeyCounts() (uploaded, unuploaded int, err error)
+}
+
+// SignalMaintenanceStore contains methods for finding and removing unused Signal sessions, identities and sender keys.
+type SignalMaintenanceStore interface {
+	GetSessionAddresses() ([]string, error)
+	GetStaleSessions(unusedSince time.Time) ([]string, error)
+	GetStaleIdentities(unusedSince time.Time) ([]string, error)
+	GetSenderKeyChats() ([]string, error)
+	DeleteSenderKeysInChat(chat string) error
+}
+
 type Device struct {
 	Log waLog.Logger
 
@@ -143,18 +257,29 @@ type Device struct {
 
 	FacebookUUID uuid.UUID
 
-	Initialized   bool
-	Identities    IdentityStore
-	Sessions      SessionStore
-	PreKeys       PreKeyStore
-	SenderKeys    SenderKeyStore
-	AppStateKeys  AppStateSyncKeyStore
-	AppState      AppStateStore
-	Contacts      ContactStore
-	ChatSettings  ChatSettingsStore
-	MsgSecrets    MsgSecretStore
-	PrivacyTokens PrivacyTokenStore
-	Container     DeviceContainer
+	Initialized    bool
+	Identities     IdentityStore
+	Sessions       SessionStore
+	PreKeys        PreKeyStore
+	SenderKeys     SenderKeyStore
+	AppStateKeys   AppStateSyncKeyStore
+	AppState       AppStateStore
+	Contacts       ContactStore
+	ChatSettings   ChatSettingsStore
+	MsgSecrets     MsgSecretStore
+	PrivacyTokens  PrivacyTokenStore
+	BroadcastLists BroadcastListStore
+	Polls          PollStore
+	Reactions      ReactionStore
+	EditHistory    EditHistoryStore
+	MessageStatus  MessageStatusStore
+	Disappearing   DisappearingTimerStore
+	MessageSearch  MessageSearchStore // Only set if the search index is enabled in the store container.
+	HistorySyncs   HistorySyncFailureStore
+	Verifications  IdentityVerificationStore
+	PreKeyHealth   PreKeyMaintenanceStore
+	SignalCleanup  SignalMaintenanceStore
+	Container      DeviceContainer
 
 	DatabaseErrorHandler func(device *Device, action string, attemptIndex int, err error) (retry bool)
 }
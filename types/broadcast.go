@@ -0,0 +1,19 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+)
+
+// BroadcastListInfo contains information about a broadcast list.
+type BroadcastListInfo struct {
+	JID        JID
+	Name       string
+	CreatedAt  time.Time
+	Recipients []JID
+}
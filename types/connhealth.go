@@ -0,0 +1,43 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+)
+
+// ConnectionHealthState is the health of the websocket connection as seen by the keepalive loop.
+type ConnectionHealthState string
+
+const (
+	// ConnectionHealthDisconnected means there's no websocket connection.
+	ConnectionHealthDisconnected ConnectionHealthState = "disconnected"
+	// ConnectionHealthHealthy means keepalive pings are being answered and frames are arriving normally.
+	ConnectionHealthHealthy ConnectionHealthState = "healthy"
+	// ConnectionHealthDegraded means recent keepalive pings have failed, but the connection isn't considered dead yet.
+	ConnectionHealthDegraded ConnectionHealthState = "degraded"
+	// ConnectionHealthDead means the connection was determined to be dead (e.g. half-open) and is being reconnected.
+	ConnectionHealthDead ConnectionHealthState = "dead"
+)
+
+// ConnectionHealth contains the health state and round-trip time statistics of the current connection.
+type ConnectionHealth struct {
+	State ConnectionHealthState
+
+	LastRTT     time.Duration
+	SmoothedRTT time.Duration
+	MinRTT      time.Duration
+	MaxRTT      time.Duration
+
+	PingsSent   int
+	PingsFailed int
+	// The number of consecutive failed pings since the last successful one.
+	ConsecutiveFailures int
+
+	LastPingSuccess   time.Time
+	LastFrameReceived time.Time
+}
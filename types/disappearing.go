@@ -0,0 +1,26 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+)
+
+// DisappearingTimer contains the disappearing message timer of a chat.
+type DisappearingTimer struct {
+	Chat  JID
+	Timer time.Duration // Zero if disappearing messages are disabled.
+	// The time when the timer was changed. Used to ignore outdated changes, e.g. from history syncs.
+	SettingTimestamp time.Time
+}
+
+// ExpiringMessage is a disappearing message with a known expiration time.
+type ExpiringMessage struct {
+	Chat      JID
+	ID        MessageID
+	ExpiresAt time.Time
+}
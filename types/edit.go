@@ -0,0 +1,25 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+)
+
+// MessageVersion is a single version of a message in its edit history.
+type MessageVersion struct {
+	Chat   JID
+	ID     MessageID // The ID of the original message.
+	Sender JID
+	// The ID of the edit message that created this version. Empty for the original version.
+	EditID MessageID
+	// The time when this version was created. For the original version, this is the message timestamp.
+	EditTS  time.Time
+	Content *waProto.Message
+}
@@ -69,6 +69,52 @@ type KeepAliveTimeout struct {
 	LastSuccess time.Time
 }
 
+// OwnDevicesChanged is emitted when the list of devices linked to the user's own account changes.
+//
+// Devices contains the new device list. Added and Removed are only filled if the previous list was known.
+type OwnDevicesChanged struct {
+	Added   []types.JID
+	Removed []types.JID
+	Devices []types.JID
+}
+
+// PreKeysLow is emitted when the server reports that fewer than MinCount one-time prekeys are left.
+// The client will upload more prekeys automatically.
+type PreKeysLow struct {
+	ServerCount int
+	MinCount    int
+}
+
+// SignedPreKeyRotated is emitted after the signed prekey is rotated.
+type SignedPreKeyRotated struct {
+	PreviousID uint32
+	NewID      uint32
+}
+
+// ConnectionHealthChanged is emitted when the health state of the connection changes,
+// e.g. when keepalive pings start failing or the connection is determined to be half-open.
+// See Client.GetConnectionHealth for querying the current state.
+type ConnectionHealthChanged struct {
+	Previous types.ConnectionHealthState
+	Health   types.ConnectionHealth
+}
+
+// ReconnectAttempt is emitted before each automatic reconnection attempt.
+//
+// The attempt number starts from 1 and is reset after a successful connection. LastError is the error from the
+// previous attempt, or nil if this is the first one. The wait can be skipped by calling Client.Reconnect.
+type ReconnectAttempt struct {
+	Attempt   int
+	Delay     time.Duration
+	LastError error
+}
+
+// ReconnectGaveUp is emitted when the client stops automatically reconnecting because the Client.ReconnectPolicy said so.
+type ReconnectGaveUp struct {
+	Attempts  int
+	LastError error
+}
+
 // KeepAliveRestored is emitted if the keepalive pings start working again after some KeepAliveTimeout events.
 // Note that if the websocket disconnects before the pings start working, this event will not be emitted.
 type KeepAliveRestored struct{}
@@ -228,10 +274,23 @@ type StreamError struct {
 type Disconnected struct{}
 
 // HistorySync is emitted when the phone has sent a blob of historical messages.
+//
+// If Client.StreamHistorySync is enabled, the conversations are emitted as HistorySyncConversation events
+// before this event, and Data.Conversations will be empty.
 type HistorySync struct {
 	Data *waProto.HistorySync
 }
 
+// HistorySyncConversation is emitted for each conversation in a history sync blob when Client.StreamHistorySync is enabled.
+//
+// If decoding the blob fails partway, the conversations emitted before the failure aren't emitted again when
+// the download is retried.
+type HistorySyncConversation struct {
+	SyncType     waProto.HistorySync_HistorySyncType
+	ChunkOrder   uint32
+	Conversation *waProto.Conversation
+}
+
 type DecryptFailMode string
 
 const (
@@ -365,6 +424,9 @@ type ChatPresence struct {
 	types.MessageSource
 	State types.ChatPresence      // The current state, either composing or paused
 	Media types.ChatPresenceMedia // When composing, the type of message
+	// True if this is a paused state generated locally, because the composing state
+	// wasn't refreshed within Client.ComposingTimeout.
+	TimedOut bool
 }
 
 // Presence is emitted when a presence update is received.
@@ -402,6 +464,10 @@ type GroupInfo struct {
 	Announce  *types.GroupAnnounce  // Group announce status change (can only admins send messages?)
 	Ephemeral *types.GroupEphemeral // Disappearing messages change
 
+	MemberAddMode          *types.GroupMemberAddMode          // Group member add mode change (can only admins add members?)
+	MembershipApprovalMode *types.GroupMembershipApprovalMode // Group join approval change (do admins have to approve new members?)
+	CommunitySettings      *types.GroupCommunitySettings      // Community settings change (can non-admins add groups?)
+
 	Delete *types.GroupDelete
 
 	Link   *types.GroupLinkChange
@@ -442,6 +508,9 @@ type IdentityChange struct {
 	// Implicit will be set to true if the event was triggered by an untrusted identity error,
 	// rather than an identity change notification from the server.
 	Implicit bool
+	// WasVerified will be set to true if the user's previous identity was manually verified.
+	// If Client.BlockSendToChangedIdentity is enabled, sending messages to the user will fail until it's verified again.
+	WasVerified bool
 }
 
 // PrivacySettings is emitted when the user changes their privacy settings.
@@ -520,6 +589,76 @@ type BlocklistChange struct {
 	Action BlocklistChangeAction
 }
 
+// PollUpdated is emitted when a vote is received for a poll that is tracked in the device store.
+//
+// If the user changed their vote, the new vote replaces the old one in Results.
+type PollUpdated struct {
+	Chat   types.JID
+	PollID types.MessageID
+	Vote   types.PollVote
+	// The names of the options in the vote. Hashes that don't match any option are omitted.
+	SelectedOptions []string
+	Results         *types.PollResults
+}
+
+// ReactionsChanged is emitted when a reaction is added, changed or removed on a message.
+//
+// Reaction contains the individual change (with an empty Text for removals),
+// while Reactions contains the current state of all reactions to the message.
+type ReactionsChanged struct {
+	Reaction  types.Reaction
+	Reactions *types.MessageReactions
+}
+
+// MessageEdited is emitted after a message edit has been validated and stored in the edit history.
+// This is only emitted if Client.TrackMessageEdits is enabled.
+//
+// Edits that weren't sent by the original sender or that were sent after the edit window are ignored.
+// Edits of messages whose original version isn't stored can't be validated, so they're stored without
+// emitting this event. The normal events.Message with IsEdit set is still emitted for all edits.
+type MessageEdited struct {
+	Info       types.MessageInfo // Information about the edit message itself
+	OriginalID types.MessageID
+	EditTS     time.Time
+	// The previous content of the message.
+	OldContent *waProto.Message
+	NewContent *waProto.Message
+}
+
+// MessageStatusChanged is emitted when the aggregated status of a message sent by this client changes,
+// e.g. when it's first sent or when another recipient reads it. This is only emitted if Client.TrackMessageStatus is enabled.
+type MessageStatusChanged struct {
+	Status         *types.MessageStatusInfo
+	PreviousStatus types.MessageStatus
+}
+
+// MessagesExpired is emitted when Client.SweepExpiredMessages deletes the locally stored data of disappearing messages.
+//
+// Applications that store messages should delete the listed messages too.
+type MessagesExpired struct {
+	Messages []types.ExpiringMessage
+}
+
+// PresenceSubscriptionFailed is emitted when re-subscribing to presence updates fails after reconnecting
+// (or after receiving a new privacy token). The subscriptions are kept and will be retried on the next reconnect.
+type PresenceSubscriptionFailed struct {
+	Errors map[types.JID]error
+}
+
+// HistorySyncFailed is emitted when a history sync blob couldn't be downloaded or decoded.
+//
+// The failed chunk is stored and retried automatically until it succeeds (in which case a normal HistorySync event
+// is emitted) or until the maximum number of attempts is reached. See Client.GetMissingHistorySyncChunks.
+type HistorySyncFailed struct {
+	MessageID  types.MessageID
+	SyncType   waProto.HistorySyncNotification_HistorySyncType
+	ChunkOrder uint32
+	Error      error
+	Attempts   int
+	// The time when the download will be retried next. Zero if it won't be retried automatically anymore.
+	NextRetry time.Time
+}
+
 type NewsletterJoin struct {
 	types.NewsletterMetadata
 }
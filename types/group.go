@@ -13,7 +13,8 @@ import (
 type GroupMemberAddMode string
 
 const (
-	GroupMemberAddModeAdmin GroupMemberAddMode = "admin_add"
+	GroupMemberAddModeAdmin     GroupMemberAddMode = "admin_add"
+	GroupMemberAddModeAllMember GroupMemberAddMode = "all_member_add"
 )
 
 // GroupInfo contains basic information about a group chat on WhatsApp.
@@ -27,8 +28,10 @@ type GroupInfo struct {
 	GroupAnnounce
 	GroupEphemeral
 	GroupIncognito
+	GroupMembershipApprovalMode
 
 	GroupParent
+	GroupCommunitySettings
 	GroupLinkedParent
 	GroupIsDefaultSub
 
@@ -40,11 +43,22 @@ type GroupInfo struct {
 	MemberAddMode GroupMemberAddMode
 }
 
+// CommunityInfo contains information about a community along with the JID of its announcement group.
+type CommunityInfo struct {
+	GroupInfo
+	AnnouncementGroupJID JID
+}
+
 type GroupParent struct {
 	IsParent                      bool
 	DefaultMembershipApprovalMode string // request_required
 }
 
+// GroupCommunitySettings contains settings that only apply to communities (parent groups).
+type GroupCommunitySettings struct {
+	AllowNonAdminSubGroupCreation bool
+}
+
 type GroupLinkedParent struct {
 	LinkedParentJID JID
 }
@@ -84,6 +98,11 @@ type GroupIncognito struct {
 	IsIncognito bool
 }
 
+// GroupMembershipApprovalMode specifies whether admins must approve new members joining via invite link.
+type GroupMembershipApprovalMode struct {
+	IsJoinApprovalRequired bool
+}
+
 // GroupParticipant contains info about a participant of a WhatsApp group chat.
 type GroupParticipant struct {
 	JID          JID
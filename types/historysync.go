@@ -0,0 +1,25 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+
+	waProto "github.com/sofyan48/whatsmeow/binary/proto"
+)
+
+// MissingHistorySyncChunk contains info about a history sync chunk that couldn't be downloaded or decoded.
+type MissingHistorySyncChunk struct {
+	MessageID  MessageID // The ID of the protocol message that contained the history sync notification.
+	SyncType   waProto.HistorySyncNotification_HistorySyncType
+	ChunkOrder uint32
+	Attempts   int
+	LastError  string
+	FailedAt   time.Time
+	// The time when the download will be retried next. Zero if it won't be retried automatically anymore.
+	NextRetry time.Time
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+)
+
+// SafetyNumber contains the Signal safety number (also known as the security code) for a conversation with another user.
+type SafetyNumber struct {
+	JID JID
+	// The 60-digit safety number, usually displayed as 12 groups of 5 digits.
+	Digits string
+	// The data to encode in a QR code that the other user can scan to verify the safety number.
+	Scannable []byte
+}
+
+// IdentityStatus contains the current identity key of a user and whether it has been verified.
+type IdentityStatus struct {
+	JID         JID
+	IdentityKey [32]byte
+
+	// True if the user's identity was manually verified at some point.
+	Verified   bool
+	VerifiedAt time.Time
+	// True if the identity was verified before, but the current identity key is different from the verified one.
+	Changed bool
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+)
+
+// MessageStatus is the aggregated delivery status of a sent message, i.e. the ticks shown in WhatsApp clients.
+//
+// The statuses are ordered, so a higher value means the message has progressed further.
+type MessageStatus int
+
+const (
+	MessageStatusUnknown   MessageStatus = iota
+	MessageStatusSent                    // The server acknowledged the message.
+	MessageStatusDelivered               // The message was delivered to all recipients.
+	MessageStatusRead                    // The message was read by all recipients.
+	MessageStatusPlayed                  // The media in the message was played by all recipients.
+)
+
+// String returns a human-readable name for the status.
+func (ms MessageStatus) String() string {
+	switch ms {
+	case MessageStatusSent:
+		return "sent"
+	case MessageStatusDelivered:
+		return "delivered"
+	case MessageStatusRead:
+		return "read"
+	case MessageStatusPlayed:
+		return "played"
+	default:
+		return "unknown"
+	}
+}
+
+// MessageStatusInfo contains the receipts received for a single sent message.
+//
+// Receipts are folded per user rather than per device, so a user is counted as soon as any of their devices
+// sends a receipt. Read receipts imply delivery and played receipts imply both delivery and reading.
+type MessageStatusInfo struct {
+	Chat   JID
+	ID     MessageID
+	SentAt time.Time
+	// The number of users the message was sent to, excluding the sender. Zero if it's not known.
+	Recipients int
+
+	DeliveredTo map[JID]time.Time
+	ReadBy      map[JID]time.Time
+	PlayedBy    map[JID]time.Time
+}
+
+// Status returns the aggregated status of the message.
+func (msi *MessageStatusInfo) Status() MessageStatus {
+	switch {
+	case msi.isReachedByAll(msi.PlayedBy):
+		return MessageStatusPlayed
+	case msi.isReachedByAll(msi.ReadBy):
+		return MessageStatusRead
+	case msi.isReachedByAll(msi.DeliveredTo):
+		return MessageStatusDelivered
+	default:
+		return MessageStatusSent
+	}
+}
+
+func (msi *MessageStatusInfo) isReachedByAll(users map[JID]time.Time) bool {
+	if msi.Recipients <= 0 {
+		// If the recipient count isn't known, a single receipt is the best guess.
+		return len(users) > 0
+	}
+	return len(users) >= msi.Recipients
+}
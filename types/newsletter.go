@@ -148,13 +148,31 @@ type NewsletterText struct {
 
 type NewsletterMessage struct {
 	MessageServerID MessageServerID
+	MessageID       MessageID
 	ViewsCount      int
 	ReactionCounts  map[string]int
+	// Vote counts for poll messages, keyed by the hex-encoded SHA-256 hash of the option name
+	PollVoteCounts map[string]int
 
 	// This is only present when fetching messages, not in live updates
 	Message *waProto.Message
 }
 
+// NewsletterPollOption contains the name and vote count of a single option in a newsletter poll.
+type NewsletterPollOption struct {
+	Name  string
+	Hash  []byte
+	Votes int
+}
+
+// NewsletterPollResults contains the aggregated results of a poll sent to a newsletter.
+type NewsletterPollResults struct {
+	MessageServerID MessageServerID
+	Name            string
+	Options         []NewsletterPollOption
+	TotalVotes      int
+}
+
 type GraphQLErrorExtensions struct {
 	ErrorCode   int    `json:"error_code"`
 	IsRetryable bool   `json:"is_retryable"`
@@ -0,0 +1,52 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+)
+
+// PollOption is a single option in a poll. The hash is the SHA-256 hash of the name, which is what votes refer to.
+type PollOption struct {
+	Name string
+	Hash []byte
+}
+
+// PollInfo contains the question and options of a poll.
+type PollInfo struct {
+	Chat      JID
+	Sender    JID
+	ID        MessageID
+	Timestamp time.Time
+
+	Name                   string
+	Options                []PollOption
+	SelectableOptionsCount int
+}
+
+// PollVote contains the latest vote of a single user in a poll.
+//
+// Voters can change their vote, in which case the new vote replaces the old one.
+// An empty list of selected options means the voter removed their vote.
+type PollVote struct {
+	Voter           JID
+	SelectedOptions [][]byte
+	Timestamp       time.Time
+}
+
+// PollOptionResult contains a poll option and the users who have currently voted for it.
+type PollOptionResult struct {
+	PollOption
+	Voters []JID
+}
+
+// PollResults contains the aggregated votes of a poll.
+type PollResults struct {
+	Poll        PollInfo
+	Options     []PollOptionResult
+	TotalVoters int
+}
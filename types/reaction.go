@@ -0,0 +1,31 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+)
+
+// Reaction contains the latest reaction of a single user to a message.
+//
+// An empty Text means the user removed their reaction.
+type Reaction struct {
+	Chat      JID
+	MessageID MessageID
+	Sender    JID
+	Text      string
+	Timestamp time.Time
+}
+
+// MessageReactions contains the current reactions to a single message.
+type MessageReactions struct {
+	Chat      JID
+	MessageID MessageID
+	Reactions []Reaction
+	// The number of users who have reacted with each emoji.
+	Counts map[string]int
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2024 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"time"
+)
+
+// Message types that are stored in the message search index.
+const (
+	SearchMessageTypeText     = "text"
+	SearchMessageTypeImage    = "image"
+	SearchMessageTypeVideo    = "video"
+	SearchMessageTypeDocument = "document"
+)
+
+// MessageSearchEntry is a single message in the message search index.
+type MessageSearchEntry struct {
+	Chat      JID
+	Sender    JID
+	ID        MessageID
+	Type      string // One of the SearchMessageType constants.
+	Timestamp time.Time
+	// The indexed text, i.e. the message text, media caption or document file name and caption.
+	Text string
+}
+
+// MessageSearchQuery contains the parameters for searching messages.
+//
+// All the filters are optional, except Query, which must contain at least one word.
+type MessageSearchQuery struct {
+	// The words to search for. All words must be present in a message for it to match.
+	Query string
+
+	Chat   JID
+	Sender JID
+	Since  time.Time
+	Until  time.Time
+	Types  []string // One or more of the SearchMessageType constants.
+
+	// The maximum number of results to return. Defaults to 50 if unset.
+	Limit int
+}
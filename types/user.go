@@ -26,6 +26,25 @@ type UserInfo struct {
 	Devices      []JID
 }
 
+// LinkedDevice contains info about a device linked to the user's own account.
+//
+// The device list query only returns device IDs and key indexes. The server doesn't expose the platform
+// of other companion devices or the last activity time of any device, so there's no last active field,
+// and Platform is only filled for the primary device and the current device.
+type LinkedDevice struct {
+	JID JID
+	// The index of the device's key in the signed key index list. Always zero for the primary device.
+	KeyIndex int
+	// True if this is the primary device (i.e. the phone).
+	IsPrimary bool
+	// True if this is the device the client is running as.
+	IsCurrent bool
+	// The platform of the device. For the primary device, this is the platform name sent by the server
+	// during pairing (e.g. android or iphone). For the current device, it's the OS name in store.DeviceProps.
+	// Empty for other companion devices.
+	Platform string
+}
+
 // ProfilePictureInfo contains the ID and URL for a WhatsApp user's profile picture or group's photo.
 type ProfilePictureInfo struct {
 	URL  string `json:"url"`  // The full URL for the image, can be downloaded with a simple HTTP request.